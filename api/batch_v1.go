@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+)
+
+func init() {
+	Register(Endpoint{
+		Name:   "v1/batch",
+		Method: http.MethodPost,
+		Params: []Param{
+			{Name: "items", In: "body", Type: "array", Required: true, Example: []interface{}{map[string]interface{}{"expression": "x^2 - 1"}}, Description: fmt.Sprintf("Up to %d requests in the same form as the body of 'v1/factor', as a JSON array or as NDJSON", maxBatchItems)},
+		},
+		MediaTypes:  []string{"application/json", "application/x-ndjson"},
+		Description: "Factor many polynomials at once, responding with the result or the error of each of them in the order they were given.",
+		Handler:     BatchV1,
+		Request:     []FactorRequestV1{},
+		Response:    []BatchItemJSON{},
+	})
+}
+
+// The most items that a batch can contain.
+const maxBatchItems = 1000
+
+// Struct defining the JSON representation of the outcome of one item of a batch, which has either a result or an error.
+type BatchItemJSON struct {
+	Index  int          `json:"index"` // The position of the item in the request, starting at 0
+	Result *FactorJSON  `json:"result,omitempty"`
+	Error  *ProblemJSON `json:"error,omitempty"`
+}
+
+// API function for factoring many polynomials at once. The body of the POST request is either a JSON array of
+// FactorRequestV1 objects, or NDJSON with one on each line, where blank lines are skipped. The polynomials are factored
+// concurrently by a bounded number of workers, and the response has a BatchItemJSON for each of them in the order they
+// were given, as a JSON array or as NDJSON to match the request. An item that is invalid or fails to factor gets an
+// error of its own without failing the rest of the batch.
+func BatchV1(w http.ResponseWriter, r *http.Request) {
+	t, b, ok := readBody(w, r, "application/json", "application/x-ndjson")
+	if !ok {
+		return
+	}
+
+	var (
+		items   [][]byte
+		problem *ProblemJSON
+	)
+	if t == "application/x-ndjson" {
+		items = splitNDJSON(b)
+	} else {
+		items, problem = splitJSONArray(b)
+	}
+	switch {
+	case problem != nil:
+	case len(items) == 0:
+		problem = newProblem(EmptyBatch, "", "Batch must contain at least one item")
+	case len(items) > maxBatchItems:
+		problem = newProblem(TooManyItems, "", "Batch must not contain more than %d items", maxBatchItems)
+	}
+	if problem != nil {
+		writeProblem(w, problem)
+		return
+	}
+
+	results := factorBatch(r.Context(), items)
+	if r.Context().Err() == context.Canceled {
+		return // The client went away, so there is no one to write the results to
+	}
+
+	// The response is written in the same format as the request
+	var buf bytes.Buffer
+	if t == "application/x-ndjson" {
+		enc := json.NewEncoder(&buf)
+		for _, v := range results {
+			if e := enc.Encode(v); e != nil {
+				writeProblem(w, newProblem(InternalError, "", "Failed to factor"))
+				log.Println(e)
+				return
+			}
+		}
+	} else if b, e := json.MarshalIndent(results, "", "  "); e != nil {
+		writeProblem(w, newProblem(InternalError, "", "Failed to factor"))
+		log.Println(e)
+		return
+	} else {
+		buf.Write(b)
+	}
+
+	w.Header().Set("Content-Type", t)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Split NDJSON into its items, skipping blank lines.
+func splitNDJSON(b []byte) [][]byte {
+	var r [][]byte
+	for _, v := range bytes.Split(b, []byte("\n")) {
+		if v = bytes.TrimSpace(v); len(v) > 0 {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
+
+// Split a JSON array into its items, returning a problem if the body isn't a single JSON array.
+func splitJSONArray(b []byte) ([][]byte, *ProblemJSON) {
+	var items []json.RawMessage
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	if e := d.Decode(&items); e != nil {
+		switch e := e.(type) {
+		case *json.SyntaxError:
+			return nil, newProblem(InvalidBody, "", "Could not parse request body at byte %d: %s", e.Offset, e.Error())
+		case *json.UnmarshalTypeError:
+			return nil, newProblem(InvalidBody, "", "Request body must be a JSON array")
+		}
+		if e == io.EOF {
+			return nil, newProblem(InvalidBody, "", "Request body must not be empty")
+		}
+		return nil, newProblem(InvalidBody, "", "Could not parse request body: %s", e.Error())
+	} else if d.Decode(&struct{}{}) != io.EOF {
+		return nil, newProblem(InvalidBody, "", "Request body must contain a single JSON array")
+	}
+
+	r := make([][]byte, len(items))
+	for i, v := range items {
+		r[i] = v
+	}
+	return r, nil
+}
+
+// Factor every item of a batch, with no more workers at a time than there are threads to run them on. Once the context
+// is done, the items that are left are given errors without being factored.
+func factorBatch(ctx context.Context, items [][]byte) []BatchItemJSON {
+	var (
+		results = make([]BatchItemJSON, len(items))
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+		workers = runtime.GOMAXPROCS(0)
+	)
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j] = factorBatchItem(ctx, j, items[j])
+			}
+		}()
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Factor one item of a batch. A panic while factoring is turned into an error, so that it can't bring down the rest of
+// the batch.
+func factorBatchItem(ctx context.Context, i int, b []byte) (r BatchItemJSON) {
+	r.Index = i
+	defer func() {
+		if v := recover(); v != nil {
+			r.Result, r.Error = nil, newProblem(InternalError, "", "Failed to factor")
+			log.Println(v)
+		}
+	}()
+
+	p, opts, problem := parseFactorRequestV1(b)
+	if problem != nil {
+		r.Error = problem
+		return r
+	}
+
+	resp, e := factorWith(ctx, p, opts)
+	switch e {
+	case nil:
+		r.Result = resp
+	case context.DeadlineExceeded, context.Canceled:
+		r.Error = newProblem(Timeout, "", "Factoring took longer than the server allows")
+	case poly.ErrNotConverged:
+		r.Error = newProblem(NotConverged, "", "The roots could not be approximated; try a lower precision")
+	case errRootTooLarge:
+		r.Error = newProblem(RootTooLarge, "", "A root is too large to be written as a number in the response")
+	default:
+		r.Error = newProblem(InternalError, "", "Failed to factor")
+		log.Println(e)
+	}
+	return r
+}
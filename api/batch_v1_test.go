@@ -0,0 +1,128 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("the BatchV1 function", func() {
+	post := func(contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "https://example.com", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+
+		api.BatchV1(w, r)
+		return w
+	}
+	batch := func(body string) []api.BatchItemJSON {
+		w := post("application/json", body)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var items []api.BatchItemJSON
+		Expect(json.Unmarshal(w.Body.Bytes(), &items)).To(Succeed())
+		return items
+	}
+
+	It("should factor every item of a JSON array in order", func() {
+		items := batch(`[{"expression": "x^2 - 1"}, {"coefficients": [10, 7, 1]}, {"expression": "x^2 + 1", "domain": "complex"}]`)
+		Expect(items).To(HaveLen(3))
+		for i, v := range items {
+			Expect(v.Index).To(Equal(i))
+			Expect(v.Error).To(BeNil())
+		}
+		Expect(items[0].Result.Factored.Expression).To(Equal("(x + 1)(x - 1)"))
+		Expect(items[1].Result.Factored.Expression).To(Equal("(x + 5)(x + 2)"))
+		Expect(items[2].Result.Factored.Expression).To(Equal("(x + i)(x - i)"))
+	})
+	It("should give each invalid item an error without failing the batch", func() {
+		items := batch(`[{"expression": "x^2 +"}, {"expression": "x^2 - 4"}, {"expr": "x^2"}, 5]`)
+		Expect(items).To(HaveLen(4))
+		Expect(items[0].Error.Code).To(Equal(api.InvalidExpression))
+		Expect(items[0].Error.Status).To(Equal(http.StatusUnprocessableEntity))
+		Expect(items[0].Result).To(BeNil())
+		Expect(items[1].Error).To(BeNil())
+		Expect(items[1].Result.Factored.Expression).To(Equal("(x + 2)(x - 2)"))
+		Expect(items[2].Error.Code).To(Equal(api.UnknownField))
+		Expect(items[2].Error.Param).To(Equal("expr"))
+		Expect(items[3].Error.Detail).To(Equal("Request body must be a JSON object"))
+	})
+	It("should factor many items concurrently", func() {
+		var requests []string
+		for i := 1; i <= 200; i++ {
+			requests = append(requests, fmt.Sprintf(`{"coefficients": [%d, 0, -1]}`, i*i))
+		}
+
+		items := batch("[" + strings.Join(requests, ",") + "]")
+		Expect(items).To(HaveLen(200))
+		for i, v := range items {
+			Expect(v.Index).To(Equal(i))
+			Expect(v.Result.Factored.Intercepts).To(Equal([]string{fmt.Sprint(-(i + 1)), fmt.Sprint(i + 1)}))
+		}
+	})
+	It("should give every item a timeout error once the deadline has passed", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "https://example.com", strings.NewReader(`[{"expression": "x^2 - 1"}, {"expression": "x^3 - 1"}]`))
+		r.Header.Set("Content-Type", "application/json")
+		api.BatchV1(w, r.WithContext(ctx))
+
+		var items []api.BatchItemJSON
+		Expect(json.Unmarshal(w.Body.Bytes(), &items)).To(Succeed())
+		Expect(items).To(HaveLen(2))
+		for _, v := range items {
+			Expect(v.Result).To(BeNil())
+			Expect(v.Error.Code).To(Equal(api.Timeout))
+		}
+	})
+	It("should answer NDJSON with NDJSON", func() {
+		w := post("application/x-ndjson", "{\"expression\": \"x^2 - 9\"}\n\n{\"expression\": \"x\"}\n{\"expression\": \"x^3 - x\", \"format\": \"latex\"}\n")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+		var items []api.BatchItemJSON
+		s := bufio.NewScanner(w.Body)
+		for s.Scan() {
+			var v api.BatchItemJSON
+			Expect(json.Unmarshal(s.Bytes(), &v)).To(Succeed())
+			items = append(items, v)
+		}
+		Expect(items).To(HaveLen(3))
+		Expect(items[0].Result.Factored.Expression).To(Equal("(x + 3)(x - 3)"))
+		Expect(items[1].Error.Code).To(Equal(api.DegreeTooLow))
+		Expect(items[2].Index).To(Equal(2))
+		Expect(items[2].Result.Factored.Expression).To(Equal("x(x + 1)(x - 1)"))
+	})
+
+	It("should reject a body that isn't an array", func() {
+		p := readProblem(post("application/json", `{"expression": "x^2 - 1"}`))
+		Expect(p.Code).To(Equal(api.InvalidBody))
+		Expect(p.Detail).To(Equal("Request body must be a JSON array"))
+	})
+	It("should reject an empty batch", func() {
+		Expect(readProblem(post("application/json", `[]`)).Code).To(Equal(api.EmptyBatch))
+		Expect(readProblem(post("application/x-ndjson", "\n")).Code).To(Equal(api.EmptyBatch))
+	})
+	It("should reject a batch with too many items", func() {
+		body := "[" + strings.TrimSuffix(strings.Repeat(`{"expression": "x^2"},`, 1001), ",") + "]"
+		p := readProblem(post("application/json", body))
+		Expect(p.Code).To(Equal(api.TooManyItems))
+		Expect(p.Detail).To(Equal("Batch must not contain more than 1000 items"))
+	})
+	It("should reject other media types", func() {
+		w := post("text/plain", `[]`)
+		p := readProblem(w)
+		Expect(p.Status).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(p.Detail).To(Equal("Content-Type must be application/json or application/x-ndjson"))
+	})
+})
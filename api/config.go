@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The prefix of the environment variables that configure the server, like QUICK_FACTOR_LISTEN.
+const envPrefix = "QUICK_FACTOR_"
+
+// Struct defining the configuration of the server and its router.
+type Config struct {
+	Prefix   string         `yaml:"prefix"` // The path that the API is routed under, which is empty to route it at the root
+	Listen   string         `yaml:"listen"` // The address to listen on, like ":8080"
+	Server   string         `yaml:"server"` // Either "unit" to run under NGINX Unit, "http" to run a server of its own, or "auto" to choose
+	CORS     CORSConfig     `yaml:"cors"`
+	Timeouts TimeoutsConfig `yaml:"timeouts"`
+	TLS      TLSConfig      `yaml:"tls"`
+}
+
+// Struct defining which cross-origin requests browsers are allowed to make.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowedOrigins"` // Origins like "https://example.com", or "*" for any origin
+	AllowedMethods []string `yaml:"allowedMethods"`
+	AllowedHeaders []string `yaml:"allowedHeaders"` // Request headers, or "*" for any header
+	MaxAge         int      `yaml:"maxAge"`         // How many seconds browsers may cache a preflight response for, or 0 to leave it up to them
+}
+
+// Struct defining how long the server waits for things. A timeout of 0 never expires. Only the factor timeout applies
+// under NGINX Unit, and the others only when the server runs a server of its own.
+type TimeoutsConfig struct {
+	Read     time.Duration `yaml:"read"`     // For a request to be read, including its body
+	Write    time.Duration `yaml:"write"`    // For a response to be written, counted from when the request was read
+	Idle     time.Duration `yaml:"idle"`     // For the next request on a connection that is kept alive
+	Shutdown time.Duration `yaml:"shutdown"` // For requests that are in progress to finish when the server is stopped
+	Factor   time.Duration `yaml:"factor"`   // For a request to be factored, after which the factoring is abandoned
+}
+
+// Struct defining the certificate that the server uses for HTTPS when it runs a server of its own. The server uses
+// plain HTTP if neither file is given.
+type TLSConfig struct {
+	CertFile string `yaml:"certFile"` // A PEM certificate, followed by any intermediate certificates
+	KeyFile  string `yaml:"keyFile"`  // The PEM private key of the certificate
+}
+
+// The configuration used when nothing else is given, which routes the API where it has always been and allows requests
+// from any origin.
+func DefaultConfig() Config {
+	return Config{
+		Prefix: "/projects/quick-factor/api",
+		Listen: ":8080",
+		Server: "auto",
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			AllowedHeaders: []string{"Content-Type"},
+		},
+		Timeouts: TimeoutsConfig{
+			Read:     10 * time.Second,
+			Write:    2 * time.Minute,
+			Idle:     2 * time.Minute,
+			Shutdown: 30 * time.Second,
+			Factor:   30 * time.Second,
+		},
+	}
+}
+
+// Load the configuration from the command line arguments 'args', the environment variables looked up with 'getenv',
+// and the YAML file named by the '-config' flag or the QUICK_FACTOR_CONFIG variable, if there is one. Flags take
+// precedence over the environment, which takes precedence over the file, which takes precedence over DefaultConfig.
+// A server of "auto" is resolved to "unit" if the program was started by NGINX Unit, and "http" if it wasn't.
+//  LoadConfig([]string{"-listen", ":9000"}, os.Getenv) -> Config{Listen: ":9000", ...}
+func LoadConfig(args []string, getenv func(string) string) (Config, error) {
+	var (
+		fs    = flag.NewFlagSet("quick-factor", flag.ContinueOnError)
+		file  = fs.String("config", "", "A YAML file to read the configuration from")
+		flags = map[string]*string{
+			"prefix":           fs.String("prefix", "", "The path that the API is routed under"),
+			"listen":           fs.String("listen", "", "The address to listen on"),
+			"server":           fs.String("server", "", "Either 'unit' to run under NGINX Unit, 'http' to run a server of its own, or 'auto' to choose"),
+			"cors-origins":     fs.String("cors-origins", "", "A comma-separated list of the origins allowed to make cross-origin requests, or '*' for any"),
+			"cors-methods":     fs.String("cors-methods", "", "A comma-separated list of the methods allowed in cross-origin requests"),
+			"cors-headers":     fs.String("cors-headers", "", "A comma-separated list of the headers allowed in cross-origin requests, or '*' for any"),
+			"cors-max-age":     fs.String("cors-max-age", "", "How many seconds browsers may cache a preflight response for"),
+			"read-timeout":     fs.String("read-timeout", "", "How long to wait for a request to be read, like '10s'"),
+			"write-timeout":    fs.String("write-timeout", "", "How long to wait for a response to be written, like '2m'"),
+			"idle-timeout":     fs.String("idle-timeout", "", "How long to keep an idle connection open, like '2m'"),
+			"shutdown-timeout": fs.String("shutdown-timeout", "", "How long to wait for requests in progress to finish when stopping, like '30s'"),
+			"factor-timeout":   fs.String("factor-timeout", "", "How long to spend factoring for a request before giving up, like '30s'"),
+			"tls-cert":         fs.String("tls-cert", "", "A PEM certificate file to serve HTTPS with"),
+			"tls-key":          fs.String("tls-key", "", "The PEM private key file of the certificate"),
+		}
+	)
+	if e := fs.Parse(args); e != nil {
+		return Config{}, e
+	} else if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected argument '%s'", fs.Arg(0))
+	}
+
+	c := DefaultConfig()
+	if *file == "" {
+		*file = getenv(envPrefix + "CONFIG")
+	}
+	if *file != "" {
+		b, e := ioutil.ReadFile(*file)
+		if e != nil {
+			return Config{}, e
+		}
+		if e := yaml.UnmarshalStrict(b, &c); e != nil {
+			return Config{}, fmt.Errorf("could not parse %s: %v", *file, e)
+		}
+	}
+
+	// Each flag has an environment variable named after it, which is used if the flag isn't given
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for name, v := range flags {
+		if set[name] {
+			continue
+		}
+		if s, ok := lookupEnv(getenv, envPrefix+strings.ToUpper(strings.ReplaceAll(name, "-", "_"))); ok {
+			*v, set[name] = s, true
+		}
+	}
+
+	for name, v := range flags {
+		if !set[name] {
+			continue
+		}
+		switch name {
+		case "prefix":
+			c.Prefix = *v
+		case "listen":
+			c.Listen = *v
+		case "server":
+			c.Server = *v
+		case "cors-origins":
+			c.CORS.AllowedOrigins = splitList(*v)
+		case "cors-methods":
+			c.CORS.AllowedMethods = splitList(*v)
+		case "cors-headers":
+			c.CORS.AllowedHeaders = splitList(*v)
+		case "cors-max-age":
+			n, e := strconv.Atoi(strings.TrimSpace(*v))
+			if e != nil {
+				return Config{}, fmt.Errorf("cors-max-age must be an integer, not '%s'", *v)
+			}
+			c.CORS.MaxAge = n
+		case "read-timeout", "write-timeout", "idle-timeout", "shutdown-timeout", "factor-timeout":
+			d, e := time.ParseDuration(strings.TrimSpace(*v))
+			if e != nil {
+				return Config{}, fmt.Errorf("%s must be a duration like '30s', not '%s'", name, *v)
+			}
+			switch name {
+			case "read-timeout":
+				c.Timeouts.Read = d
+			case "write-timeout":
+				c.Timeouts.Write = d
+			case "idle-timeout":
+				c.Timeouts.Idle = d
+			case "shutdown-timeout":
+				c.Timeouts.Shutdown = d
+			case "factor-timeout":
+				c.Timeouts.Factor = d
+			}
+		case "tls-cert":
+			c.TLS.CertFile = *v
+		case "tls-key":
+			c.TLS.KeyFile = *v
+		}
+	}
+
+	// NGINX Unit tells the programs that it starts how to talk to it through this variable
+	if c.Server == "auto" {
+		if getenv("NXT_UNIT_INIT") != "" {
+			c.Server = "unit"
+		} else {
+			c.Server = "http"
+		}
+	}
+
+	return c, c.normalize()
+}
+
+// Check that the configuration is valid, and put it in the form that the router expects: a prefix with a leading slash
+// and no trailing slash, and methods in uppercase.
+func (c *Config) normalize() error {
+	if c.Prefix = strings.TrimRight(strings.TrimSpace(c.Prefix), "/"); c.Prefix != "" && !strings.HasPrefix(c.Prefix, "/") {
+		c.Prefix = "/" + c.Prefix
+	}
+
+	if strings.TrimSpace(c.Listen) == "" {
+		return errors.New("listen address must not be empty")
+	}
+
+	switch c.Server {
+	case "http":
+	case "unit":
+		if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+			return errors.New("tls can't be used with NGINX Unit, which serves HTTPS itself")
+		}
+	default:
+		return fmt.Errorf("server must be one of 'unit', 'http' or 'auto', not '%s'", c.Server)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("tls-cert and tls-key must be given together")
+	}
+	for _, v := range []time.Duration{c.Timeouts.Read, c.Timeouts.Write, c.Timeouts.Idle, c.Timeouts.Shutdown, c.Timeouts.Factor} {
+		if v < 0 {
+			return errors.New("timeouts must not be negative")
+		}
+	}
+
+	for i, v := range c.CORS.AllowedMethods {
+		c.CORS.AllowedMethods[i] = strings.ToUpper(v)
+	}
+	if c.CORS.MaxAge < 0 {
+		return errors.New("cors-max-age must not be negative")
+	}
+
+	return nil
+}
+
+// Look up an environment variable, which counts as being set if it isn't empty.
+func lookupEnv(getenv func(string) string, name string) (string, bool) {
+	s := getenv(name)
+	return s, s != ""
+}
+
+// Split a comma-separated list, leaving out empty items.
+//  splitList("GET, POST,") -> [GET POST]
+func splitList(s string) []string {
+	r := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
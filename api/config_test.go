@@ -0,0 +1,206 @@
+package api_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var _ = Describe("the configuration", func() {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string {
+			return vars[name]
+		}
+	}
+	var dirs []string
+	AfterEach(func() {
+		for _, v := range dirs {
+			Expect(os.RemoveAll(v)).To(Succeed())
+		}
+		dirs = nil
+	})
+	writeFile := func(contents string) string {
+		dir, e := ioutil.TempDir("", "quick-factor")
+		Expect(e).NotTo(HaveOccurred())
+		dirs = append(dirs, dir)
+
+		name := filepath.Join(dir, "config.yaml")
+		Expect(ioutil.WriteFile(name, []byte(contents), 0600)).To(Succeed())
+		return name
+	}
+
+	It("should use the defaults when nothing is given", func() {
+		c, e := api.LoadConfig(nil, env(nil))
+		Expect(e).NotTo(HaveOccurred())
+
+		expected := api.DefaultConfig()
+		expected.Server = "http"
+		Expect(c).To(Equal(expected))
+	})
+	It("should run under NGINX Unit when it was started by it", func() {
+		c, e := api.LoadConfig(nil, env(map[string]string{"NXT_UNIT_INIT": "3,4,5"}))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(c.Server).To(Equal("unit"))
+
+		c, e = api.LoadConfig([]string{"-server", "http"}, env(map[string]string{"NXT_UNIT_INIT": "3,4,5"}))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(c.Server).To(Equal("http"))
+	})
+	It("should read a YAML file, then the environment, then flags", func() {
+		file := writeFile(`
+prefix: /factoring/
+listen: ":9000"
+cors:
+  allowedOrigins: [https://a.example]
+  allowedHeaders: [Content-Type, X-Request-Id]
+  maxAge: 600
+timeouts:
+  read: 5s
+  shutdown: 1m
+`)
+		c, e := api.LoadConfig([]string{"-config", file, "-listen", "127.0.0.1:9002"}, env(map[string]string{
+			"QUICK_FACTOR_LISTEN":         ":9001",
+			"QUICK_FACTOR_CORS_METHODS":   "get, post, options",
+			"QUICK_FACTOR_IDLE_TIMEOUT":   "90s",
+			"QUICK_FACTOR_FACTOR_TIMEOUT": "5m",
+			"QUICK_FACTOR_TLS_CERT":       "cert.pem",
+			"QUICK_FACTOR_TLS_KEY":        "key.pem",
+		}))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(c).To(Equal(api.Config{
+			Prefix: "/factoring",
+			Listen: "127.0.0.1:9002",
+			Server: "http",
+			CORS: api.CORSConfig{
+				AllowedOrigins: []string{"https://a.example"},
+				AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "X-Request-Id"},
+				MaxAge:         600,
+			},
+			Timeouts: api.TimeoutsConfig{
+				Read:     5 * time.Second,
+				Write:    2 * time.Minute,
+				Idle:     90 * time.Second,
+				Shutdown: time.Minute,
+				Factor:   5 * time.Minute,
+			},
+			TLS: api.TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+		}))
+	})
+	It("should find the file from the environment", func() {
+		file := writeFile("prefix: api\n")
+		c, e := api.LoadConfig(nil, env(map[string]string{"QUICK_FACTOR_CONFIG": file}))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(c.Prefix).To(Equal("/api"))
+	})
+	It("should reject invalid configuration", func() {
+		_, e := api.LoadConfig([]string{"-cors-max-age", "soon"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-listen", " "}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-unknown"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-server", "apache"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-write-timeout", "forever"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-factor-timeout", "-1s"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-tls-cert", "cert.pem"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-server", "unit", "-tls-cert", "cert.pem", "-tls-key", "key.pem"}, env(nil))
+		Expect(e).To(HaveOccurred())
+		_, e = api.LoadConfig([]string{"-config", writeFile("lisen: ':80'\n")}, env(nil))
+		Expect(e).To(MatchError(ContainSubstring("lisen")))
+	})
+
+	Describe("the router", func() {
+		serve := func(c api.Config, r *http.Request) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			api.NewRouter(c).ServeHTTP(w, r)
+			return w
+		}
+		restricted := api.DefaultConfig()
+		restricted.Prefix = "/factoring"
+		restricted.CORS = api.CORSConfig{
+			AllowedOrigins: []string{"https://a.example"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type"},
+			MaxAge:         600,
+		}
+
+		It("should route the API under the prefix", func() {
+			w := serve(restricted, httptest.NewRequest("GET", "/factoring/factor?expr=x%5E2-1", nil))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"result": "full"`))
+
+			Expect(serve(restricted, httptest.NewRequest("GET", "/projects/quick-factor/api/factor?expr=x%5E2-1", nil)).Code).To(Equal(http.StatusNotFound))
+		})
+		It("should route the API at the root when the prefix is empty", func() {
+			c := api.DefaultConfig()
+			c.Prefix = ""
+			Expect(serve(c, httptest.NewRequest("GET", "/factor?expr=x%5E2-1", nil)).Code).To(Equal(http.StatusOK))
+			Expect(serve(c, httptest.NewRequest("GET", "/", nil)).Body.String()).To(ContainSubstring(`"available":`))
+		})
+		It("should only allow the configured origins", func() {
+			r := httptest.NewRequest("GET", "/factoring/factor?expr=x%5E2-1", nil)
+			r.Header.Set("Origin", "https://a.example")
+			w := serve(restricted, r)
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://a.example"))
+			Expect(w.Header().Values("Vary")).To(ContainElement("Origin"))
+
+			r.Header.Set("Origin", "https://b.example")
+			Expect(serve(restricted, r).Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+		It("should answer a preflight request", func() {
+			r := httptest.NewRequest("OPTIONS", "/factoring/v1/factor", nil)
+			r.Header.Set("Origin", "https://a.example")
+			r.Header.Set("Access-Control-Request-Method", "POST")
+			r.Header.Set("Access-Control-Request-Headers", "content-type")
+
+			w := serve(restricted, r)
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://a.example"))
+			Expect(w.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, POST"))
+			Expect(w.Header().Get("Access-Control-Allow-Headers")).To(Equal("Content-Type"))
+			Expect(w.Header().Get("Access-Control-Max-Age")).To(Equal("600"))
+		})
+		It("should reject a preflight request for something that isn't allowed", func() {
+			r := httptest.NewRequest("OPTIONS", "/factoring/v1/factor", nil)
+			r.Header.Set("Origin", "https://a.example")
+			r.Header.Set("Access-Control-Request-Method", "DELETE")
+			w := serve(restricted, r)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(readProblem(w).Code).To(Equal(api.PreflightRejected))
+
+			r.Header.Set("Access-Control-Request-Method", "POST")
+			r.Header.Set("Access-Control-Request-Headers", "X-Secret")
+			Expect(readProblem(serve(restricted, r)).Param).To(Equal("Access-Control-Request-Headers"))
+
+			r.Header.Set("Origin", "https://b.example")
+			r.Header.Del("Access-Control-Request-Headers")
+			w = serve(restricted, r)
+			Expect(readProblem(w).Param).To(Equal("Origin"))
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+		It("should give up on factoring that takes longer than the factor timeout", func() {
+			c := api.DefaultConfig()
+			c.Timeouts.Factor = time.Nanosecond
+			w := serve(c, httptest.NewRequest("GET", "/projects/quick-factor/api/factor?expr=x%5E3-6x%5E2%2B11x-6", nil))
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(readProblem(w).Code).To(Equal(api.Timeout))
+
+			c.Timeouts.Factor = 0
+			Expect(serve(c, httptest.NewRequest("GET", "/projects/quick-factor/api/factor?expr=x%5E3-6x%5E2%2B11x-6", nil)).Code).To(Equal(http.StatusOK))
+		})
+		It("should describe the prefix in the OpenAPI document", func() {
+			Expect(serve(restricted, httptest.NewRequest("GET", "/factoring/openapi.json", nil)).Body.String()).To(ContainSubstring(`"url": "/factoring"`))
+		})
+	})
+})
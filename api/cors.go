@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Wrap a handler so that it follows the CORS policy. Requests from allowed origins are given the headers that let
+// browsers read their responses, and preflight requests are answered here without reaching the handler, with the
+// methods and headers that are allowed, or with a problem if the request they ask about isn't allowed.
+func (c CORSConfig) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// The response depends on the origin unless every origin gets the same one
+		if !contains(c.AllowedOrigins, "*") {
+			w.Header().Add("Vary", "Origin")
+		}
+		allowed := c.allowOrigin(w, origin)
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		method := r.Header.Get("Access-Control-Request-Method")
+		headers := splitList(r.Header.Get("Access-Control-Request-Headers"))
+		switch {
+		case !allowed:
+			writeProblem(w, newProblem(PreflightRejected, "Origin", "Origin '%s' is not allowed", origin))
+			return
+		case !contains(c.AllowedMethods, method):
+			writeProblem(w, newProblem(PreflightRejected, "Access-Control-Request-Method", "Method '%s' is not allowed", method))
+			return
+		}
+		for _, v := range headers {
+			if !contains(c.AllowedHeaders, "*") && !containsFold(c.AllowedHeaders, v) {
+				writeProblem(w, newProblem(PreflightRejected, "Access-Control-Request-Headers", "Header '%s' is not allowed", v))
+				return
+			}
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+		if contains(c.AllowedHeaders, "*") {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", ")) // The wildcard isn't understood by every browser
+		} else if len(c.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		}
+		if c.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Set the header that allows the origin to read the response, returning whether it is allowed to.
+func (c CORSConfig) allowOrigin(w http.ResponseWriter, origin string) bool {
+	switch {
+	case contains(c.AllowedOrigins, "*"):
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return true
+	case origin != "" && contains(c.AllowedOrigins, origin):
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		return true
+	}
+
+	return false
+}
+
+// Whether a list contains a string.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether a list contains a string, ignoring case, as header names do.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,380 @@
+// Package expr represents mathematical expressions as trees, so that the same expression can be written as plain text,
+// ASCII, LaTeX or presentation MathML without any of them being pieced together from the others.
+package expr
+
+import (
+	"html"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A way of writing an expression.
+type Format string
+
+const (
+	Text   Format = "text"   // Plain text with Unicode radicals, like "(x - √2)^2"
+	ASCII  Format = "ascii"  // Plain text with nothing but ASCII characters, like "(x - sqrt(2))^2"
+	LaTeX  Format = "latex"  // LaTeX without delimiters, like "(x - \sqrt{2})^{2}"
+	MathML Format = "mathml" // A presentation MathML <math> element
+)
+
+type kind int
+
+const (
+	number kind = iota
+	variable
+	sum
+	product
+	negation
+	fraction
+	power
+	root
+)
+
+// A node in an expression tree. The zero value is the number 0.
+type Node struct {
+	kind  kind
+	name  string // The digits of a number or the name of a variable
+	args  []Node // The terms of a sum, the factors of a product, or the operand of anything else
+	index int    // The exponent of a power or the index of a root
+}
+
+// The imaginary unit.
+var I = Var("i")
+
+// A non-negative number, written with its digits as they are given.
+//  Number("1.5") -> 1.5
+func Number(digits string) Node {
+	return Node{kind: number, name: digits}
+}
+
+// An integer, which is negated if it is negative.
+func Int(v *big.Int) Node {
+	if v.Sign() < 0 {
+		return Neg(Number(new(big.Int).Abs(v).String()))
+	}
+
+	return Number(v.String())
+}
+
+// A rational number, written as a fraction unless it is an integer.
+//  Rat(-7/3) -> -7/3
+func Rat(v *big.Rat) Node {
+	if v.IsInt() {
+		return Int(v.Num())
+	}
+
+	return Frac(Int(v.Num()), Int(v.Denom()))
+}
+
+// A variable, like x.
+func Var(name string) Node {
+	return Node{kind: variable, name: name}
+}
+
+// The sum of some terms. Negated terms are subtracted, and sums within the sum are flattened, since addition is
+// associative.
+func Sum(terms ...Node) Node {
+	var args []Node
+	for _, v := range terms {
+		if v.kind == sum {
+			args = append(args, v.args...)
+		} else {
+			args = append(args, v)
+		}
+	}
+
+	switch len(args) {
+	case 0:
+		return Number("0")
+	case 1:
+		return args[0]
+	}
+	return Node{kind: sum, args: args}
+}
+
+// The product of some factors, which are written next to each other. Factors of 1 are left out, and products within the
+// product are flattened.
+func Product(factors ...Node) Node {
+	var args []Node
+	for _, v := range factors {
+		switch {
+		case v.kind == number && v.name == "1":
+		case v.kind == product:
+			args = append(args, v.args...)
+		default:
+			args = append(args, v)
+		}
+	}
+
+	switch len(args) {
+	case 0:
+		return Number("1")
+	case 1:
+		return args[0]
+	}
+	return Node{kind: product, args: args}
+}
+
+// The negation of an expression. Negating a negation cancels it out.
+func Neg(n Node) Node {
+	if n.kind == negation {
+		return n.args[0]
+	}
+
+	return Node{kind: negation, args: []Node{n}}
+}
+
+// The quotient of two expressions. Signs are moved out in front of the fraction, and a denominator of 1 is left out.
+//  Frac(-√3, 2) -> -√3 / 2
+func Frac(numerator, denominator Node) Node {
+	switch {
+	case denominator.kind == number && denominator.name == "1":
+		return numerator
+	case numerator.kind == negation:
+		return Neg(Frac(numerator.args[0], denominator))
+	case denominator.kind == negation:
+		return Neg(Frac(numerator, denominator.args[0]))
+	}
+
+	return Node{kind: fraction, args: []Node{numerator, denominator}}
+}
+
+// An expression raised to a positive integer power. A power of 1 is left out.
+func Pow(base Node, exponent int) Node {
+	if exponent == 1 {
+		return base
+	}
+
+	return Node{kind: power, args: []Node{base}, index: exponent}
+}
+
+// The square root of an expression.
+func Sqrt(radicand Node) Node {
+	return Node{kind: root, args: []Node{radicand}, index: 2}
+}
+
+// The cube root of an expression.
+func Cbrt(radicand Node) Node {
+	return Node{kind: root, args: []Node{radicand}, index: 3}
+}
+
+// Write the expression as plain text.
+func (n Node) String() string {
+	return Text.Write(n)
+}
+
+// Write an expression in the format.
+//  Text.Write(Frac(Sum(Number("1"), Sqrt(Number("5"))), Number("2"))) -> "(1 + √5) / 2"
+//  LaTeX.Write(Frac(Sum(Number("1"), Sqrt(Number("5"))), Number("2"))) -> "\frac{1 + \sqrt{5}}{2}"
+func (f Format) Write(n Node) string {
+	if f == MathML {
+		return `<math xmlns="http://www.w3.org/1998/Math/MathML">` + f.write(n) + `</math>`
+	}
+
+	return f.write(n)
+}
+
+// Write a node, which in MathML is always a single element.
+func (f Format) write(n Node) string {
+	switch n.kind {
+	case number:
+		if f == MathML {
+			return "<mn>" + html.EscapeString(n.name) + "</mn>"
+		}
+		return n.name
+	case variable:
+		if f == MathML {
+			return "<mi>" + html.EscapeString(n.name) + "</mi>"
+		}
+		return n.name
+	case sum:
+		return f.writeSum(n.args)
+	case product:
+		return f.writeProduct(n.args)
+	case negation:
+		return f.row(f.op("-") + f.operand(n.args[0], n.args[0].kind == sum))
+	case fraction:
+		return f.writeFraction(n.args[0], n.args[1])
+	case power:
+		base := f.operand(n.args[0], n.args[0].kind != number && n.args[0].kind != variable)
+		exponent := strconv.Itoa(n.index)
+		switch f {
+		case LaTeX:
+			return base + "^{" + exponent + "}"
+		case MathML:
+			return "<msup>" + base + "<mn>" + exponent + "</mn></msup>"
+		}
+		return base + "^" + exponent
+	default:
+		return f.writeRoot(n.args[0], n.index)
+	}
+}
+
+// Write the terms of a sum, with the sign of each negated term as its operator.
+func (f Format) writeSum(terms []Node) string {
+	var b strings.Builder
+	for i, v := range terms {
+		negated := v.kind == negation
+		if negated {
+			v = v.args[0]
+		}
+
+		switch {
+		case i == 0 && negated:
+			b.WriteString(f.op("-"))
+		case i == 0:
+		case negated:
+			b.WriteString(f.infix("-"))
+		default:
+			b.WriteString(f.infix("+"))
+		}
+		b.WriteString(f.operand(v, v.kind == sum))
+	}
+
+	return f.row(b.String())
+}
+
+// Write the factors of a product next to each other. Sums and negations are always bracketed, and in plain text
+// fractions are bracketed too, as are roots that something comes after, so that nothing is misread as being part of
+// them. A multiplication sign is only used where two numbers would otherwise run together, or in ASCII, where a root is
+// multiplied.
+//  Text.Write(Product(Frac(Number("3"), Number("2")), I)) -> "(3/2)i"
+func (f Format) writeProduct(factors []Node) string {
+	var b strings.Builder
+	for i, v := range factors {
+		bracketed := v.kind == sum || v.kind == negation
+		if f == Text || f == ASCII {
+			bracketed = bracketed || v.kind == fraction || (f == Text && v.kind == root && i < len(factors)-1)
+		}
+
+		if i > 0 {
+			switch {
+			case !bracketed && f.leadingDigit(v):
+				b.WriteString(f.times())
+			case f == ASCII && (v.kind == root || factors[i-1].kind == root):
+				b.WriteString("*")
+			case f == MathML:
+				b.WriteString("<mo>&#x2062;</mo>") // Invisible times
+			}
+		}
+		b.WriteString(f.operand(v, bracketed))
+	}
+
+	return f.row(b.String())
+}
+
+// Write a fraction. In plain text, a fraction of two numbers is written without spaces, and anything else has spaces
+// around the slash and brackets around any part that would otherwise be misread.
+//  Text.Write(Frac(Number("7"), Number("3"))) -> "7/3"
+func (f Format) writeFraction(numerator, denominator Node) string {
+	switch f {
+	case LaTeX:
+		return `\frac{` + f.write(numerator) + "}{" + f.write(denominator) + "}"
+	case MathML:
+		return "<mfrac>" + f.write(numerator) + f.write(denominator) + "</mfrac>"
+	}
+
+	if numerator.kind == number && denominator.kind == number {
+		return numerator.name + "/" + denominator.name
+	}
+	return f.operand(numerator, numerator.kind == sum || numerator.kind == fraction) + " / " +
+		f.operand(denominator, denominator.kind == sum || denominator.kind == fraction || denominator.kind == negation)
+}
+
+// Write a square or cube root. In plain text, the radicand is bracketed unless it is a number.
+//  Text.Write(Cbrt(Number("2"))) -> "∛2"
+//  ASCII.Write(Cbrt(Number("2"))) -> "cbrt(2)"
+func (f Format) writeRoot(radicand Node, index int) string {
+	switch f {
+	case ASCII:
+		if index == 3 {
+			return "cbrt(" + f.write(radicand) + ")"
+		}
+		return "sqrt(" + f.write(radicand) + ")"
+	case LaTeX:
+		if index == 3 {
+			return `\sqrt[3]{` + f.write(radicand) + "}"
+		}
+		return `\sqrt{` + f.write(radicand) + "}"
+	case MathML:
+		if index == 3 {
+			return "<mroot>" + f.write(radicand) + "<mn>3</mn></mroot>"
+		}
+		return "<msqrt>" + f.write(radicand) + "</msqrt>"
+	}
+
+	symbol := "√"
+	if index == 3 {
+		symbol = "∛"
+	}
+	return symbol + f.operand(radicand, radicand.kind != number)
+}
+
+// Write a node, in brackets if they are needed.
+func (f Format) operand(n Node, bracketed bool) string {
+	if !bracketed {
+		return f.write(n)
+	} else if f == MathML {
+		return "<mrow><mo>(</mo>" + f.write(n) + "<mo>)</mo></mrow>"
+	}
+
+	return "(" + f.write(n) + ")"
+}
+
+// Whether a node written as a factor starts with a digit, so that it can't be written straight after another number.
+// In LaTeX and MathML, a fraction after a number would look like a mixed number, so it counts too.
+func (f Format) leadingDigit(n Node) bool {
+	switch n.kind {
+	case number:
+		return true
+	case product, power:
+		return f.leadingDigit(n.args[0])
+	case fraction:
+		return f == LaTeX || f == MathML
+	default:
+		return false
+	}
+}
+
+// The multiplication sign.
+func (f Format) times() string {
+	switch f {
+	case ASCII:
+		return "*"
+	case LaTeX:
+		return `\cdot `
+	case MathML:
+		return "<mo>&#x22C5;</mo>"
+	}
+
+	return "·"
+}
+
+// An operator written on its own, like a leading minus sign.
+func (f Format) op(s string) string {
+	if f == MathML {
+		return "<mo>" + s + "</mo>"
+	}
+
+	return s
+}
+
+// An operator written between two operands.
+func (f Format) infix(s string) string {
+	if f == MathML {
+		return "<mo>" + s + "</mo>"
+	}
+
+	return " " + s + " "
+}
+
+// Group the elements of a node into a single element in MathML.
+func (f Format) row(s string) string {
+	if f == MathML {
+		return "<mrow>" + s + "</mrow>"
+	}
+
+	return s
+}
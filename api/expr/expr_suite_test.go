@@ -0,0 +1,13 @@
+package expr_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestExpr(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expr Suite")
+}
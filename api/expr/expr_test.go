@@ -0,0 +1,57 @@
+package expr_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("writing expressions", func() {
+	var (
+		x        = expr.Var("x")
+		n        = expr.Number
+		halfRoot = expr.Frac(expr.Sum(n("1"), expr.Neg(expr.Sqrt(n("3")))), n("2"))
+	)
+
+	DescribeTable("in each format",
+		func(e expr.Node, text, ascii, latex string) {
+			Expect(expr.Text.Write(e)).To(Equal(text))
+			Expect(e.String()).To(Equal(text))
+			Expect(expr.ASCII.Write(e)).To(Equal(ascii))
+			Expect(expr.LaTeX.Write(e)).To(Equal(latex))
+		},
+		Entry("a power with more than one digit", expr.Pow(x, 10), "x^10", "x^10", "x^{10}"),
+		Entry("a polynomial", expr.Sum(expr.Product(n("3"), expr.Pow(x, 2)), expr.Neg(expr.Product(n("2"), x)), n("1")), "3x^2 - 2x + 1", "3x^2 - 2x + 1", "3x^{2} - 2x + 1"),
+		Entry("a product of repeated factors", expr.Neg(expr.Product(n("6"), expr.Pow(expr.Sum(x, expr.Neg(n("1"))), 2))), "-6(x - 1)^2", "-6(x - 1)^2", "-6(x - 1)^{2}"),
+		Entry("a surd over a denominator", halfRoot, "(1 - √3) / 2", "(1 - sqrt(3)) / 2", `\frac{1 - \sqrt{3}}{2}`),
+		Entry("a fraction of integers", expr.Rat(big.NewRat(-7, 3)), "-7/3", "-7/3", `-\frac{7}{3}`),
+		Entry("a fraction times i", expr.Product(expr.Rat(big.NewRat(3, 2)), expr.I), "(3/2)i", "(3/2)i", `\frac{3}{2}i`),
+		Entry("a root times i", expr.Product(expr.Sqrt(n("3")), expr.I), "(√3)i", "sqrt(3)*i", `\sqrt{3}i`),
+		Entry("i times a root", expr.Product(n("2"), expr.I, expr.Sqrt(n("3"))), "2i√3", "2i*sqrt(3)", `2i\sqrt{3}`),
+		Entry("a cube root of a sum", expr.Cbrt(expr.Sum(n("9"), expr.Sqrt(n("69")))), "∛(9 + √69)", "cbrt(9 + sqrt(69))", `\sqrt[3]{9 + \sqrt{69}}`),
+		Entry("a cube root of a number", expr.Neg(expr.Cbrt(n("2"))), "-∛2", "-cbrt(2)", `-\sqrt[3]{2}`),
+		Entry("two numbers multiplied", expr.Product(expr.Cbrt(n("2")), n("3")), "(∛2)·3", "cbrt(2)*3", `\sqrt[3]{2}\cdot 3`),
+		Entry("a negated sum", expr.Sum(x, expr.Neg(expr.Sum(n("1"), expr.Sqrt(n("2"))))), "x - (1 + √2)", "x - (1 + sqrt(2))", `x - (1 + \sqrt{2})`),
+	)
+
+	It("should simplify as it builds the tree", func() {
+		Expect(expr.Product(n("1"), x).String()).To(Equal("x"))
+		Expect(expr.Pow(x, 1).String()).To(Equal("x"))
+		Expect(expr.Neg(expr.Neg(x)).String()).To(Equal("x"))
+		Expect(expr.Frac(expr.Neg(expr.Sqrt(n("3"))), n("2")).String()).To(Equal("-√3 / 2"))
+		Expect(expr.Frac(x, n("1")).String()).To(Equal("x"))
+		Expect(expr.Sum(x, expr.Sum(n("1"), n("2"))).String()).To(Equal("x + 1 + 2"))
+	})
+
+	It("should write presentation MathML", func() {
+		Expect(expr.MathML.Write(expr.Pow(x, 10))).To(Equal(`<math xmlns="http://www.w3.org/1998/Math/MathML"><msup><mi>x</mi><mn>10</mn></msup></math>`))
+		Expect(expr.MathML.Write(halfRoot)).To(Equal(`<math xmlns="http://www.w3.org/1998/Math/MathML"><mfrac><mrow><mn>1</mn><mo>-</mo><msqrt><mn>3</mn></msqrt></mrow><mn>2</mn></mfrac></math>`))
+		Expect(expr.MathML.Write(expr.Product(n("2"), expr.Sum(x, n("1"))))).To(Equal(`<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mn>2</mn><mo>&#x2062;</mo><mrow><mo>(</mo><mrow><mi>x</mi><mo>+</mo><mn>1</mn></mrow><mo>)</mo></mrow></mrow></math>`))
+	})
+
+	It("should escape names in MathML", func() {
+		Expect(expr.MathML.Write(expr.Var("<"))).To(Equal(`<math xmlns="http://www.w3.org/1998/Math/MathML"><mi>&lt;</mi></math>`))
+	})
+})
@@ -1,465 +1,342 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
 	"log"
 	"math"
+	"math/big"
 	"net/http"
-	"regexp"
-	"sort"
+	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 )
 
 func init() {
-	funcs = append(funcs, Factor)
+	Register(Endpoint{
+		Name:   "factor",
+		Method: http.MethodGet,
+		Params: append(polynomialParams(),
+			Param{Name: "complex", In: "query", Type: "boolean", Default: false, Description: "Whether to factor over the complex numbers"},
+			Param{Name: "mode", In: "query", Type: "string", Default: "exact", Enum: []string{"exact", "radical", "numeric"}, Description: "How far to go when there are no more rational roots"},
+			Param{Name: "precision", In: "query", Type: "integer", Default: defaultDigits, Example: 12, Description: fmt.Sprintf("The number of decimal digits to approximate roots to in numeric mode, from 1 to %d", poly.MaxDigits)},
+			Param{Name: "steps", In: "query", Type: "boolean", Default: false, Description: "Whether to include the working"},
+			Param{Name: "format", In: "query", Type: "string", Default: string(expr.Text), Enum: []string{string(expr.Text), string(expr.ASCII), string(expr.LaTeX), string(expr.MathML)}, Description: "How to write the expression and exact roots"},
+		),
+		Description: "Factor a polynomial. Parameters may also be sent as a form in the body of a POST request.",
+		Handler:     Factor,
+		Response:    FactorJSON{},
+	})
 }
 
+// The parameters that give a polynomial to the GET endpoints, either as an expression or by its coefficients.
+func polynomialParams() []Param {
+	return []Param{
+		{Name: "expr", In: "query", Type: "string", Example: "x^3 - 2x^2 - 5x + 6", Description: "The polynomial as a free-form expression, which is used instead of 'degree' and the coefficients if it is given"},
+		{Name: "degree", In: "query", Type: "integer", Example: 2, Description: "The degree of the polynomial, which must be at least 2; required unless 'expr' is given"},
+		{Name: "x^n", In: "query", Type: "object", Example: map[string]string{"x^0": "-3/4", "x^2": "1"}, Description: "The coefficient of each power of x as its own parameter, from 'x^0' to 'x^degree', each an integer, decimal or fraction that is 0 if it is left out"},
+	}
+}
+
+// The number of decimal digits that roots are approximated to in numeric mode, unless the 'precision' parameter is given.
+const defaultDigits = 10
+
+// The number of decimal places that the values of roots are written to, except in numeric mode, where they are written
+// to the precision that they were approximated to.
+const decimalPlaces = 5
+
 // Struct defining the JSON response from the API function.
 type FactorJSON struct {
-	Result   string        `json:"result"` // A string representing the factoring result; either "full", "quadratic", "partial", or "not"
+	Result   string        `json:"result"` // A string representing the factoring result; either "full", "quadratic", "radical", "numeric", "partial", or "not"
 	Factored *FactoredJSON `json:"factored,omitempty"`
+	Steps    []StepJSON    `json:"steps,omitempty"` // Only included if the 'steps' parameter is true
+}
+
+// Struct defining the JSON representation of a step in the working of a factorization.
+type StepJSON struct {
+	Kind  string `json:"kind"`  // What the step does, like "rational root", "synthetic division" or "quadratic formula"
+	Text  string `json:"text"`  // The step described in plain text
+	LaTeX string `json:"latex"` // The step described in LaTeX, with mathematics delimited by \( and \) or \[ and \]
 }
 
 // Struct defining the JSON representation of a factored polynomial.
 type FactoredJSON struct {
-	Expression string   `json:"expression"`
-	Intercepts []string `json:"intercepts,omitempty"`
+	Expression string          `json:"expression"` // Written in the format given by the 'format' parameter
+	Intercepts []string        `json:"intercepts,omitempty"`
+	Roots      []RootJSON      `json:"roots,omitempty"` // The same roots as Intercepts, along with their multiplicities
+	Constant   *RationalJSON   `json:"constant,omitempty"`
+	Factors    []ComponentJSON `json:"factors,omitempty"`  // The polynomial is Constant multiplied by each of these factors
+	Identity   string          `json:"identity,omitempty"` // The special product used to factor the polynomial, like "difference of squares"
 }
 
-// API function for factoring polynomials
-func Factor(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
+// Struct defining the JSON representation of a root of a polynomial.
+type RootJSON struct {
+	Value        string      `json:"value"`
+	Multiplicity int         `json:"multiplicity"`
+	Decimal      float64     `json:"decimal"`
+	Imaginary    float64     `json:"imaginary,omitempty"`   // The imaginary part of a complex root, whose real part is Decimal
+	Complex      bool        `json:"complex,omitempty"`     // Whether the root is complex, in which case it isn't an intercept
+	Exact        string      `json:"exact,omitempty"`       // The root written exactly in the requested format, like "7/3", "-5 + 3√3" or "-1/2 + (√3 / 2)i"
+	Approximate  string      `json:"approximate,omitempty"` // For a root found numerically, its value to the requested precision
+	Error        float64     `json:"error,omitempty"`       // For a root found numerically, the largest distance there can be to the true root
+	Numerator    json.Number `json:"numerator,omitempty"`   // Only included if the root is rational
+	Denominator  json.Number `json:"denominator,omitempty"` // Only included if the root is rational
+	Surd         *SurdJSON   `json:"surd,omitempty"`        // Only included if the root, or its imaginary part, is irrational
+}
 
-	var degree uint
-	if s := strings.Trim(q.Get("degree"), " "); s == "" { // Extra whitespace is trimmed to avoid unintentional errors
-		http.Error(w, "ERROR: Missing required query parameter 'degree'", http.StatusExpectationFailed)
-		return
-	} else if d, e := strconv.Atoi(s); e != nil || d < 2 {
-		http.Error(w, "ERROR: Query parameter 'degree' must be an integer >= 2", http.StatusExpectationFailed)
-		return
-	} else {
-		degree = uint(d) // Can be safely converted to uint because it must be a positive integer
-	}
+// Struct defining the JSON representation of a number of the form a + b√n.
+type SurdJSON struct {
+	Rational    RationalJSON `json:"rational"`    // a
+	Coefficient RationalJSON `json:"coefficient"` // b
+	Radicand    json.Number  `json:"radicand"`    // n, which is an integer without small square factors
+}
 
-	// Extract each exponent from query parameters
-	coefficients := make([]float64, degree+1)
-	for i := range coefficients {
-		// Extra whitespace is trimmed to avoid unintentional errors
-		if s := strings.Trim(q.Get(fmt.Sprintf("x^%d", i)), " "); s == "" {
-			// If an empty string is found, assume a value of 0
-			coefficients[i] = 0
-		} else if f, e := strconv.ParseFloat(s, 64); e != nil {
-			http.Error(w, fmt.Sprintf("ERROR: could not parse value in query parameter 'x^%d'", i), http.StatusExpectationFailed)
-			return
-		} else if (i == 0 || i == int(degree)) && f == 0 {
-			http.Error(w, fmt.Sprintf("ERROR: x^%d must not be 0", i), http.StatusExpectationFailed)
-			return
-		} else {
-			coefficients[i] = f
-		}
-	}
+// Struct defining the JSON representation of a single factor of a factored polynomial.
+type ComponentJSON struct {
+	Coefficients []json.Number `json:"coefficients"` // Integer coefficients, starting with the constant term
+	Multiplicity int           `json:"multiplicity"`
+	Degree       int           `json:"degree"`
+	Irreducible  bool          `json:"irreducible"`
+}
 
-	// Do the actual factoring
-	var result *FactorJSON
-	if degree == 2 {
-		result = factorTrinomial(coefficients)
-	} else {
-		result = factorPolynomial(degree, coefficients)
-	}
+// Struct defining the JSON representation of a rational number.
+type RationalJSON struct {
+	Numerator   json.Number `json:"numerator"`
+	Denominator json.Number `json:"denominator"`
+}
 
-	// Write the result
-	if b, e := json.MarshalIndent(result, "", "  "); result == nil || e != nil {
-		http.Error(w, "ERROR: failed to factor", http.StatusInternalServerError)
-		if e != nil {
-			log.Println(e)
-		}
-	} else {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write(b)
+// API function for factoring polynomials. The polynomial is either given as a free-form expression in the 'expr'
+// parameter, or by its 'degree' and the coefficient of each power of x in the 'x^0' to 'x^n' parameters. Parameters
+// may be sent in the query string or as a form in the body of a POST request. If the 'complex' parameter is true, the
+// polynomial is factored over the complex numbers. If the 'mode' parameter is "radical", cubic and quartic factors are
+// also solved with Cardano's and Ferrari's methods, and if it is "numeric", the roots of every remaining factor are
+// approximated to the number of decimal digits given by the 'precision' parameter. If the 'steps' parameter is true,
+// the working that led to the factorization is included. The 'format' parameter chooses how the expression and exact
+// roots are written: "text" by default, or "ascii", "latex" or "mathml". A request that isn't valid is answered with a
+// ProblemJSON, whose code says what was wrong and whose param says which parameter it was wrong with.
+func Factor(w http.ResponseWriter, r *http.Request) {
+	if e := r.ParseForm(); e != nil {
+		writeProblem(w, newProblem(InvalidBody, "", "Could not parse request body"))
+		return
 	}
-}
 
-// Implements specific factoring rules that can only be applied to a 3-term polynomial.
-func factorTrinomial(coefficients []float64) *FactorJSON {
-	// Definitions to avoid errors with labels
 	var (
-		result                        [2]float64
-		resultType                    = "full"
-		negativeB, discriminant, twoA float64
+		coefficients poly.Polynomial
+		problem      *ProblemJSON
 	)
-
-	// Ensure that there are the correct number of coefficients
-	if len(coefficients) != 3 {
-		log.Println("factorTrinomial called with not exactly 3 coefficients")
-		return nil
+	if s := strings.Trim(r.Form.Get("expr"), " "); s != "" { // Extra whitespace is trimmed to avoid unintentional errors
+		coefficients, problem = parseExpression(s, "Parameter", "expr")
+	} else {
+		coefficients, problem = parseCoefficients(r.Form)
 	}
-
-	// Check if any of the coefficients are not integers. If they are skip ahead to using the quadratic formula.
-	for _, v := range coefficients {
-		if v != math.Round(v) {
-			goto formula
-		}
+	if problem != nil {
+		writeProblem(w, problem)
+		return
 	}
 
-	// Grouping is done differently depending on operators (+/-)
-	if product := coefficients[0] * coefficients[2]; product < 0 {
-		if coefficients[1] < 0 {
-			for a, b := 1., coefficients[1]-1; a*b >= product; a, b = a+1, b-1 {
-				if a*b == product {
-					result = [2]float64{a * -1, b * -1}
-				}
-			}
-		} else if coefficients[1] > 0 {
-			for a, b := -1., coefficients[1]+1; a*b >= product; a, b = a-1, b+1 {
-				if a*b == product {
-					result = [2]float64{a, b}
-				}
-			}
-		} else { // coefficients[1] == 0
-			r := strconv.FormatFloat(math.Sqrt(math.Abs(product)), 'g', 5, 64)
-
-			return &FactorJSON{
-				Result: "full",
-				Factored: &FactoredJSON{
-					Expression: fmt.Sprintf("(x + %[1]s)(x - %[1]s)", r),
-					Intercepts: []string{r, "-" + r},
-				},
-			}
-		}
-	} else { // coefficients[0] and coefficients[2] both cannot be 0, so neither can 'product'
-		// If coefficients[1] == 0, it cannot be factored
-		if coefficients[1] == 0 {
-			return &FactorJSON{
-				Result: "not",
-			}
-		}
-
-		// Make sure to recall that the value should be negative
-		var negative bool
-		if coefficients[1] < 0 {
-			coefficients[1] = math.Abs(coefficients[1])
-			negative = true
-		}
-
-		half := math.Ceil(coefficients[1] / 2)
-		for a, b := 1., coefficients[1]-1; a < half; a, b = a+1, b-1 {
-			if a*b == product {
-				if negative {
-					result = [2]float64{a, b}
-				} else {
-					result = [2]float64{-1 * a, -1 * b}
-				}
-
-				break
-			}
+	// Factoring over the complex numbers is optional
+	opts := factorOptions{mode: strings.Trim(r.Form.Get("mode"), " "), digits: defaultDigits, format: expr.Text}
+	if s := strings.Trim(r.Form.Get("complex"), " "); s != "" {
+		var e error
+		if opts.complex, e = strconv.ParseBool(s); e != nil {
+			writeProblem(w, newProblem(InvalidBoolean, "complex", "Parameter 'complex' must be either true or false"))
+			return
 		}
 	}
 
-	if result == [2]float64{0, 0} {
-		goto formula
-	} else {
-		for i := range result {
-			result[i] /= coefficients[2]
+	// The working is only included when it is asked for
+	if s := strings.Trim(r.Form.Get("steps"), " "); s != "" {
+		var e error
+		if opts.steps, e = strconv.ParseBool(s); e != nil {
+			writeProblem(w, newProblem(InvalidBoolean, "steps", "Parameter 'steps' must be either true or false"))
+			return
 		}
-		goto ret
 	}
 
-formula:
-	// Calculate the components of the quadratic formula
-	negativeB = -1 * coefficients[1]
-	discriminant = math.Pow(coefficients[1], 2) - (4 * coefficients[0] * coefficients[2])
-	twoA = 2 * coefficients[2]
-
-	// If the discriminant is negative, it has no square root, so do not factor further
-	if !(discriminant > 0) {
-		// Generate x-intercept strings
-		intercepts := make([]string, 2)
-		for i, v := range []byte{'+', '-'} {
-			intercepts[i] = fmt.Sprintf("(%s %c √(%s)) / %s", formatFloat(negativeB), v, formatFloat(discriminant), formatFloat(twoA))
-		}
-
-		return &FactorJSON{
-			Result: "quadratic",
-			Factored: &FactoredJSON{
-				Expression: fmt.Sprintf("(%s)(%s)", intercepts[0], intercepts[1]),
-				Intercepts: intercepts,
-			},
-		}
+	// The expression and exact roots are written in plain text unless another format is asked for
+	if s := strings.Trim(r.Form.Get("format"), " "); s != "" {
+		opts.format = expr.Format(s)
 	}
 
-	// Factor fully using the quadratic formula and return
-	discriminant = math.Sqrt(discriminant)
-	result = [2]float64{(negativeB + discriminant) / twoA, (negativeB - discriminant) / twoA}
-	resultType = "quadratic"
-	goto ret
-
-ret:
-	sorted := result[:]
-	sort.Float64s(sorted)
-	res := make([]string, 2)
-	for i, v := range sorted {
-		res[i] = formatFloat(v)
+	// A precision that isn't an integer is left as 0, so that it is rejected if it is used
+	if s := strings.Trim(r.Form.Get("precision"), " "); s != "" {
+		opts.digits, _ = strconv.Atoi(s)
 	}
 
-	return &FactorJSON{
-		Result: resultType,
-		Factored: &FactoredJSON{
-			Expression: fmt.Sprintf("(x%s)(x%s)", getOp(sorted[0]*-1), getOp(sorted[1]*-1)),
-			Intercepts: res,
-		},
+	if problem := opts.check("Parameter"); problem != nil {
+		writeProblem(w, problem)
+		return
 	}
+	writeFactorization(r.Context(), w, coefficients, opts)
 }
 
-// Implements general factorization rules that can be applied to any polynomial.
-func factorPolynomial(degree uint, coefficients []float64) *FactorJSON {
-	// Validate degree value
-	if degree < 2 {
-		log.Println("degree was smaller than 2, this shouldn't have happened")
-		return nil
-	} else if degree == 2 {
-		log.Println("factorPolynomial was called when factorTrinomial should have been")
-	}
-	if int(degree) != len(coefficients)-1 {
-		log.Println("degree does not match up with number of coefficients")
-		return nil
-	}
+// The options for factoring a polynomial and writing the result, which both the query parameters and the JSON request
+// body are converted into.
+type factorOptions struct {
+	complex bool        // Whether to factor over the complex numbers
+	mode    string      // The solver mode, which is "exact" if it is empty
+	digits  int         // The number of decimal digits to approximate roots to in numeric mode
+	steps   bool        // Whether to include the working
+	format  expr.Format // How to write the expression and exact roots
+}
 
-	// If any coefficient is not a whole number the polynomial cannot be factored.
-	for _, v := range coefficients {
-		if v != math.Round(v) {
-			return &FactorJSON{Result: "not"}
+// Check that the options are valid, returning a problem if they aren't. Each option is called a 'kind' in the problem,
+// like "Parameter" or "Field".
+func (o factorOptions) check(kind string) *ProblemJSON {
+	switch o.mode {
+	case "", "exact", "radical":
+	case "numeric":
+		if o.digits < 1 || o.digits > poly.MaxDigits {
+			return newProblem(InvalidPrecision, "precision", "%s 'precision' must be an integer from 1 to %d", kind, poly.MaxDigits)
 		}
+	default:
+		return newProblem(InvalidMode, "mode", "%s 'mode' must be one of 'exact', 'radical' or 'numeric'", kind)
 	}
 
-	// Attempt to implement the rational root theorem
-	var (
-		wg            sync.WaitGroup
-		interceptChan = make(chan float64)
-	)
-	for _, num := range findFactorsOf(uint(math.Abs(coefficients[0]))) {
-		for _, den := range findFactorsOf(uint(math.Abs(coefficients[len(coefficients)-1]))) {
-			wg.Add(1)
-			go func(num, den float64) {
-				defer wg.Done()
-
-				x := num / den
-
-				var resultPos, resultNeg float64
-				for i := len(coefficients) - 1; i >= 0; i-- {
-					resultPos += coefficients[i] * math.Pow(x, float64(i))
-					resultNeg += coefficients[i] * math.Pow(x*-1, float64(i))
-				}
-
-				if resultPos == 0 {
-					interceptChan <- x
-				} else if resultNeg == 0 {
-					interceptChan <- x * -1
-				}
-			}(num, den)
-		}
+	switch o.format {
+	case expr.Text, expr.ASCII, expr.LaTeX, expr.MathML:
+	default:
+		return newProblem(InvalidFormat, "format", "%s 'format' must be one of 'text', 'ascii', 'latex' or 'mathml'", kind)
 	}
 
-	// Create a channel to notify when the WaitGroup is empty
-	doneWaiting := make(chan bool, 1)
-	go func() {
-		wg.Wait()
-		doneWaiting <- true
-		close(doneWaiting)
-	}()
-
-	var intercept float64
-	select {
-	case intercept = <-interceptChan:
-	case <-doneWaiting: // If this happens there are no valid factors
-		return &FactorJSON{Result: "not"}
-	}
+	return nil
+}
 
-	// Divide the polynomial by the discovered intercept
-	newCoefficients := make([]float64, len(coefficients)-1)
-	newCoefficients[len(newCoefficients)-1] = coefficients[len(coefficients)-1]
-	for i := len(coefficients) - 2; i > 0; i-- {
-		newCoefficients[i-1] = coefficients[i] + (intercept * newCoefficients[i])
-	}
-	if coefficients[0]+(intercept*newCoefficients[0]) != 0 {
-		log.Println("an intercept marked as valid was not")
-		return nil
+// Factor the polynomial with the options and write the result. Factoring stops when the context is done, which is
+// written as a timeout if its deadline passed, and not written at all if the client went away.
+func writeFactorization(ctx context.Context, w http.ResponseWriter, p poly.Polynomial, o factorOptions) {
+	resp, e := factorWith(ctx, p, o)
+	switch e {
+	case nil:
+	case context.DeadlineExceeded:
+		writeProblem(w, newProblem(Timeout, "", "Factoring took longer than the server allows"))
+		return
+	case context.Canceled:
+		return
+	case poly.ErrNotConverged:
+		writeProblem(w, newProblem(NotConverged, "", "The roots could not be approximated; try a lower precision"))
+		return
+	case errRootTooLarge:
+		writeProblem(w, newProblem(RootTooLarge, "", "A root is too large to be written as a number in the response"))
+		return
+	default:
+		writeProblem(w, newProblem(InternalError, "", "Failed to factor"))
+		log.Println(e)
+		return
 	}
 
-	// Recursion
-	var d *FactorJSON
-	if degree > 3 {
-		d = factorPolynomial(degree-1, newCoefficients)
+	if b, e := json.MarshalIndent(resp, "", "  "); e != nil {
+		writeProblem(w, newProblem(InternalError, "", "Failed to factor"))
+		log.Println(e)
 	} else {
-		d = factorTrinomial(newCoefficients)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
 	}
+}
 
-	if d.Result == "not" {
-		firstCoefficient := formatFloat(newCoefficients[len(newCoefficients)-1])
-		if firstCoefficient == "1" {
-			firstCoefficient = ""
-		} else if firstCoefficient == "-1" {
-			firstCoefficient = "-"
-		}
-
-		expr := fmt.Sprintf("(%sx^%d", firstCoefficient, len(newCoefficients)-1)
+// Returned by factorWith when a root is too large for a float64, since infinities can't be written in JSON.
+var errRootTooLarge = errors.New("api: root too large for a float64")
 
-		for i := len(newCoefficients) - 2; i > 1; i-- {
-			expr += fmt.Sprintf("%sx^%d", getOp(newCoefficients[i]), i)
-		}
-		expr += fmt.Sprintf("%sx%s)(x%s)", getOp(newCoefficients[1]), getOp(newCoefficients[0]), getOp(intercept*-1))
-
-		return &FactorJSON{
-			Result: "partial",
-			Factored: &FactoredJSON{
-				Expression: expr,
-				Intercepts: []string{formatFloat(intercept)},
-			},
-		}
-	} else {
-		d.Factored.Expression += fmt.Sprintf("(x%s)", getOp(intercept*-1))
-		d.Factored.Intercepts = append(d.Factored.Intercepts, formatFloat(intercept))
-		sort.Slice(d.Factored.Intercepts, func(i, j int) bool {
-			var strs [2]string
-			strs[0] = d.Factored.Intercepts[i]
-			strs[1] = d.Factored.Intercepts[j]
-
-			var isQuad, isNeg [2]bool
-			for i, v := range strs {
-				if strings.Contains(v, "√") {
-					isQuad[i] = true
-
-					if strings.Contains(v, "- √") {
-						isNeg[i] = true
-					}
-				}
-			}
-
-			if isQuad[0] && !isQuad[1] {
-				return false
-			} else if !isQuad[0] && isQuad[1] {
-				return true
-			} else if isQuad[0] && isQuad[1] {
-				return isQuad[1]
-			} else {
-				var flts [2]float64
-				for i, v := range strs {
-					flts[i], _ = strconv.ParseFloat(v, 64)
-				}
-				return flts[0] < flts[1]
-			}
-		})
-		for i := 0; i < (len(d.Factored.Intercepts) - 1); i++ {
-			if d.Factored.Intercepts[i] == d.Factored.Intercepts[i+1] {
-				if i+2 < len(d.Factored.Intercepts) {
-					d.Factored.Intercepts = append(d.Factored.Intercepts[:i+1], d.Factored.Intercepts[i+2:]...)
-				} else {
-					d.Factored.Intercepts = d.Factored.Intercepts[:len(d.Factored.Intercepts)-1]
-				}
-			}
-		}
+// Factor the polynomial with the options, and convert the result into the JSON response. Returns the context's error
+// if it is done before the polynomial is factored.
+func factorWith(ctx context.Context, p poly.Polynomial, o factorOptions) (*FactorJSON, error) {
+	factor := poly.FactorContext
+	if o.complex {
+		factor = poly.FactorComplexContext
+	}
 
-		var (
-			sortedExpr string
-			regex      = regexp.MustCompile(`\(x ([+-]) ([0-9])*\)(?:\^([0-9]*))?`)
-			exprs      = regex.FindAllStringSubmatch(d.Factored.Expression, -1)
-		)
-		if s := regexp.MustCompile(`^[^)]*\)`).FindString(d.Factored.Expression); !regex.MatchString(s) {
-			sortedExpr += s
+	// The solver mode decides how far to go when there are no more rational roots
+	switch o.mode {
+	case "radical":
+		factor = poly.FactorRadicalContext
+	case "numeric":
+		factor = func(ctx context.Context, p poly.Polynomial) (poly.Factorization, error) {
+			return poly.FactorNumericContext(ctx, p, o.digits)
 		}
+	}
 
-		sort.Slice(exprs, func(i, j int) bool {
-			var vals, exps [2]float64
-			for i, v := range []int{i, j} {
-				matches := regex.FindStringSubmatch(exprs[v][0])
-
-				r, _ := strconv.ParseFloat(matches[2], 64)
-				if matches[1][0] == '+' {
-					r *= -1
-				}
-				vals[i] = r
-
-				if matches[3] == "" {
-					exps[i] = 1
-				} else {
-					exps[i], _ = strconv.ParseFloat(matches[3], 64)
-				}
-			}
+	result, e := factor(ctx, p)
+	if e != nil {
+		return nil, e
+	}
 
-			if exps[0] != exps[1] {
-				return exps[0] < exps[1]
-			} else {
-				return vals[0] < vals[1]
+	places := decimalPlaces
+	if o.mode == "numeric" {
+		places = o.digits
+	}
+	resp := newFactorJSON(result, o.format, places)
+	if resp.Factored != nil {
+		for _, v := range resp.Factored.Roots {
+			if math.IsInf(v.Decimal, 0) || math.IsInf(v.Imaginary, 0) || math.IsInf(v.Error, 0) {
+				return nil, errRootTooLarge
 			}
-		})
-
-		for _, v := range exprs {
-			sortedExpr += v[0]
 		}
-
-		d.Factored.Expression = sortedExpr
-
-		return d
 	}
-}
-
-// Calculate factors of 'x'. 'x' must be positive.
-func findFactorsOf(x uint) []float64 {
-	var (
-		wg      sync.WaitGroup
-		factors = make(chan float64, x) // Buffer the maximum amount of values that can be sent, though it will likely never be necessary to have that many values in the channel
-	)
-
-	// Check that x is not 0
-	if x == 0 {
-		log.Println("there are no factors of 0, and this shouldn't have happened")
-		return nil
+	if o.steps {
+		resp.Steps = newStepsJSON(result.Steps)
 	}
+	return resp, nil
+}
 
-	// Calculate if each value from 2 to x is a factor
-	for i := uint(2); i < (x - 1); i++ {
-		wg.Add(1)
-		go func(i uint) {
-			defer wg.Done()
-			if f := float64(x) / float64(i); math.Round(f) == f {
-				factors <- f
-			}
-		}(i)
+// Parse a polynomial given as an expression, returning a problem if it is not valid. The expression is called 'name' in
+// the problem, and is a 'kind' of input, like "Parameter" or "Field".
+func parseExpression(s, kind, name string) (poly.Polynomial, *ProblemJSON) {
+	p, e := poly.Parse(s)
+	if pe, ok := e.(*poly.ParseError); ok {
+		problem := newProblem(InvalidExpression, name, "Could not parse %s '%s' at column %d: %s", strings.ToLower(kind), name, pe.Column, pe.Msg)
+		problem.Column = pe.Column
+		return nil, problem
+	} else if e != nil {
+		return nil, newProblem(InvalidExpression, name, "Could not parse %s '%s'", strings.ToLower(kind), name)
 	}
 
-	// Ensure channel gets closed at the proper time
-	go func() {
-		wg.Wait()
-		close(factors)
-	}()
-
-	// Move channel values into array and return
-	r := []float64{1} // 1 is a factor of everything
-	for v := range factors {
-		r = append(r, v)
+	// The same rules apply as when the coefficients are given individually
+	if p.Degree() < 2 {
+		return nil, newProblem(DegreeTooLow, name, "%s '%s' must have a degree >= 2", kind, name)
 	}
 
-	// Sort the array and return
-	sort.Float64s(r)
-	return r
+	return p, nil
 }
 
-// Return v formatted with the correct operator in front of it
-//  getOp(-45) -> " - 45"
-func getOp(v float64) string {
-	var r string
-	if v < 0 {
-		r = " - "
+// Parse the polynomial given in the 'degree' and 'x^0' to 'x^n' parameters, returning a problem if it is not valid.
+func parseCoefficients(q url.Values) (poly.Polynomial, *ProblemJSON) {
+	var degree uint
+	if s := strings.Trim(q.Get("degree"), " "); s == "" { // Extra whitespace is trimmed to avoid unintentional errors
+		return nil, newProblem(MissingDegree, "degree", "Missing required query parameter 'degree'")
+	} else if d, e := strconv.Atoi(s); e != nil {
+		return nil, newProblem(InvalidDegree, "degree", "Query parameter 'degree' must be an integer >= 2")
+	} else if d < 2 {
+		return nil, newProblem(DegreeTooLow, "degree", "Query parameter 'degree' must be an integer >= 2")
+	} else if d > poly.MaxParseDegree {
+		// The same limit applies as to expressions, since the coefficients are allocated before any are read
+		return nil, newProblem(DegreeTooHigh, "degree", "Query parameter 'degree' must be an integer <= %d", poly.MaxParseDegree)
 	} else {
-		r = " + "
+		degree = uint(d) // Can be safely converted to uint because it must be a positive integer
 	}
 
-	return r + formatFloat(math.Abs(v))
-}
+	// Extract each exponent from query parameters
+	coefficients := make(poly.Polynomial, degree+1)
+	for i := range coefficients {
+		// Extra whitespace is trimmed to avoid unintentional errors
+		param := fmt.Sprintf("x^%d", i)
+		if s := strings.Trim(q.Get(param), " "); s == "" {
+			// If an empty string is found, assume a value of 0
+			coefficients[i] = new(big.Rat)
+		} else if f, ok := new(big.Rat).SetString(s); !ok {
+			return nil, newProblem(InvalidCoefficient, param, "Could not parse value in query parameter '%s'", param)
+		} else {
+			coefficients[i] = f
+		}
+	}
+
+	// The leading coefficient can't be 0, whether it was given or left out
+	if coefficients[degree].Sign() == 0 {
+		return nil, newProblem(ZeroLeadingCoefficient, fmt.Sprintf("x^%d", degree), "Query parameter 'x^%d' must not be 0", degree)
+	}
 
-// Take 0's off the end of floats
-func formatFloat(v float64) string {
-	return strings.TrimRight(strings.TrimRight(strconv.FormatFloat(v, 'f', 5, 64), "0"), ".")
+	return coefficients, nil
 }
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/noahfriedman-ca/quick-factor/api"
@@ -10,11 +11,14 @@ import (
 	"io/ioutil"
 	"math"
 	"math/rand"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var _ = Describe("the Factor function", func() {
@@ -31,16 +35,563 @@ var _ = Describe("the Factor function", func() {
 
 		return string(b)
 	}
+	getProblem := func(queries string) api.ProblemJSON {
+		w := httptest.NewRecorder()
+		api.Factor(w, httptest.NewRequest("", "https://example.com?"+queries, nil))
+		return readProblem(w)
+	}
+
+	// Keep only the expression and intercepts of a response, so that tests of them aren't affected by the fields that
+	// describe roots and factors in more detail
+	basic := func(f api.FactorJSON) api.FactorJSON {
+		if f.Factored != nil {
+			f.Factored = &api.FactoredJSON{Expression: f.Factored.Expression, Intercepts: f.Factored.Intercepts}
+		}
+		return f
+	}
 
 	DescribeTable("when an error should be thrown",
-		func(degree string) {
-			resp := getResponse("degree=" + degree)
-			Expect(resp).To(ContainSubstring("ERROR:"))
+		func(queries string, code api.ProblemCode, param string, status int) {
+			p := getProblem(queries)
+			Expect(p.Code).To(Equal(code))
+			Expect(p.Param).To(Equal(param))
+			Expect(p.Status).To(Equal(status))
+		},
+		Entry("should throw an error when the 'degree' query isn't present", "degree=", api.MissingDegree, "degree", 400),
+		Entry("should throw an error when the 'degree' query isn't numeric", "degree=notanumber", api.InvalidDegree, "degree", 400),
+		Entry("should throw an error when the 'degree' query isn't an integer", "degree=3.14", api.InvalidDegree, "degree", 400),
+		Entry("should throw an error when the 'degree' query is < 2", "degree=1", api.DegreeTooLow, "degree", 422),
+		Entry("should throw an error when the 'degree' query is too large", "degree=3000&x^3000=1&x^0=-2", api.DegreeTooHigh, "degree", 422),
+		Entry("should throw an error when a coefficient isn't numeric", "degree=2&x^1=one&x^2=1", api.InvalidCoefficient, "x^1", 400),
+		Entry("should throw an error when the leading coefficient is 0", "degree=2&x^0=1", api.ZeroLeadingCoefficient, "x^2", 422),
+		Entry("should throw an error when a root is too large for a JSON number", "degree=2&x^2=1&x^0=-1e800", api.RootTooLarge, "", 422),
+	)
+
+	DescribeTable("when the polynomial is given as an expression",
+		func(expr string, expected *api.FactorJSON) {
+			resp := getResponse("expr=" + url.QueryEscape(expr))
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
+
+			Expect(basic(respJSON)).To(Equal(*expected))
+		},
+		Entry("should factor an expression in standard form",
+			"x^3 - 2x^2 - 5x + 6",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "(x + 2)(x - 1)(x - 3)", Intercepts: []string{"-2", "1", "3"}},
+			},
+		),
+		Entry("should expand parentheses before factoring",
+			"(x + 5)(x + 2)",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "(x + 5)(x + 2)", Intercepts: []string{"-5", "-2"}},
+			},
+		),
+	)
+
+	DescribeTable("when an expression is invalid",
+		func(expr string, code api.ProblemCode, detail string) {
+			p := getProblem("expr=" + url.QueryEscape(expr))
+			Expect(p.Code).To(Equal(code))
+			Expect(p.Param).To(Equal("expr"))
+			Expect(p.Detail).To(HavePrefix(detail))
+		},
+		Entry("should report the column of a parse error", "x^2 + y", api.InvalidExpression, "Could not parse parameter 'expr' at column 7"),
+		Entry("should reject a degree < 2", "2x + 1", api.DegreeTooLow, "Parameter 'expr' must have a degree >= 2"),
+	)
+
+	DescribeTable("when the constant term is 0",
+		func(queries string, expected *api.FactorJSON) {
+			resp := getResponse(queries)
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
+
+			Expect(basic(respJSON)).To(Equal(*expected))
+		},
+		Entry("should factor x out of 'x^3 - 4x'",
+			"degree=3&x^1=-4&x^3=1",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "x(x + 2)(x - 2)", Intercepts: []string{"-2", "0", "2"}},
+			},
+		),
+		Entry("should factor a power of x out of 'x^4 + 2x^3'",
+			"expr="+url.QueryEscape("x^4 + 2x^3"),
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "x^3(x + 2)", Intercepts: []string{"-2", "0"}},
+			},
+		),
+		Entry("should partially factor 'x^4 + x^2'",
+			"expr="+url.QueryEscape("x^4 + x^2"),
+			&api.FactorJSON{
+				Result:   "partial",
+				Factored: &api.FactoredJSON{Expression: "x^2(x^2 + 1)", Intercepts: []string{"0"}},
+			},
+		),
+	)
+
+	DescribeTable("when the polynomial has a common factor",
+		func(expr string, expected *api.FactorJSON) {
+			resp := getResponse("expr=" + url.QueryEscape(expr))
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
+
+			Expect(basic(respJSON)).To(Equal(*expected))
+		},
+		Entry("should keep the greatest common factor of '6x^2 + 30x + 36'",
+			"6x^2 + 30x + 36",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "6(x + 3)(x + 2)", Intercepts: []string{"-3", "-2"}},
+			},
+		),
+		Entry("should factor out a negative leading coefficient",
+			"-x^2 + 4",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "-(x + 2)(x - 2)", Intercepts: []string{"-2", "2"}},
+			},
+		),
+		Entry("should keep the leading coefficient when using the quadratic formula",
+			"2x^2 - 4",
+			&api.FactorJSON{
+				Result:   "quadratic",
+				Factored: &api.FactoredJSON{Expression: "2(x + √2)(x - √2)", Intercepts: []string{"-1.41421", "1.41421"}},
+			},
+		),
+		Entry("should keep irrational roots exact however small they are",
+			"12345678901234567x^2 - 1",
+			&api.FactorJSON{
+				Result: "quadratic",
+				Factored: &api.FactoredJSON{
+					Expression: "12345678901234567(x + √12345678901234567 / 12345678901234567)(x - √12345678901234567 / 12345678901234567)",
+					Intercepts: []string{"-9e-09", "9e-09"},
+				},
+			},
+		),
+		Entry("should keep the constant factor alongside a power of x",
+			"-3x^4 - 6x^3 - 3x^2",
+			&api.FactorJSON{
+				Result:   "full",
+				Factored: &api.FactoredJSON{Expression: "-3x^2(x + 1)^2", Intercepts: []string{"-1", "0"}},
+			},
+		),
+	)
+
+	DescribeTable("when roots are repeated",
+		func(expr string, expression string, roots []api.RootJSON) {
+			resp := getResponse("expr=" + url.QueryEscape(expr))
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Factored).NotTo(BeNil())
+			Expect(respJSON.Factored.Expression).To(Equal(expression))
+
+			// Only the values and multiplicities are being tested
+			var actual []api.RootJSON
+			for _, v := range respJSON.Factored.Roots {
+				actual = append(actual, api.RootJSON{Value: v.Value, Multiplicity: v.Multiplicity})
+			}
+			Expect(actual).To(Equal(roots))
+		},
+		Entry("should use an exponent for '(x - 2)^3'",
+			"(x - 2)^3",
+			"(x - 2)^3",
+			[]api.RootJSON{{Value: "2", Multiplicity: 3}},
+		),
+		Entry("should sort factors by their exponents",
+			"(x - 2)^3(x + 1)(x - 5)^2",
+			"(x + 1)(x - 5)^2(x - 2)^3",
+			[]api.RootJSON{{Value: "-1", Multiplicity: 1}, {Value: "2", Multiplicity: 3}, {Value: "5", Multiplicity: 2}},
+		),
+		Entry("should find a double root of a trinomial",
+			"x^2 + 4x + 4",
+			"(x + 2)^2",
+			[]api.RootJSON{{Value: "-2", Multiplicity: 2}},
+		),
+		Entry("should use an exponent for a repeated factor without roots",
+			"(x^2 + 1)^2(x - 1)",
+			"(x^2 + 1)^2(x - 1)",
+			[]api.RootJSON{{Value: "-i", Multiplicity: 2}, {Value: "i", Multiplicity: 2}, {Value: "1", Multiplicity: 1}},
+		),
+	)
+
+	Describe("the structured description of the factors", func() {
+		factor := func(expr string) *api.FactoredJSON {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape(expr))), &respJSON)).To(Succeed())
+			Expect(respJSON.Factored).NotTo(BeNil())
+			return respJSON.Factored
+		}
+
+		It("should list each factor with its coefficients", func() {
+			f := factor("-6(x - 1)^2(x^2 + x - 1)")
+			Expect(f.Constant).To(Equal(&api.RationalJSON{Numerator: "-6", Denominator: "1"}))
+			Expect(f.Factors).To(ConsistOf(
+				api.ComponentJSON{Coefficients: []json.Number{"-1", "1"}, Multiplicity: 2, Degree: 1, Irreducible: true},
+				api.ComponentJSON{Coefficients: []json.Number{"-1", "1", "1"}, Multiplicity: 1, Degree: 2, Irreducible: true},
+			))
+		})
+		It("should give rational roots exactly", func() {
+			f := factor("3x^2 - 10x + 7")
+			Expect(f.Roots).To(Equal([]api.RootJSON{
+				{Value: "1", Multiplicity: 1, Decimal: 1, Exact: "1", Numerator: "1", Denominator: "1"},
+				{Value: "2.33333", Multiplicity: 1, Decimal: 7. / 3, Exact: "7/3", Numerator: "7", Denominator: "3"},
+			}))
+		})
+		It("should give irrational roots exactly and as decimals", func() {
+			f := factor("x^2 + 10x - 2")
+			Expect(f.Roots).To(HaveLen(2))
+			Expect(f.Roots[0].Exact).To(Equal("-5 - 3√3"))
+			Expect(f.Roots[0].Decimal).To(BeNumerically("~", -10.19615, 1e-5))
+			Expect(f.Roots[0].Numerator).To(BeEmpty())
+			Expect(f.Roots[1].Exact).To(Equal("-5 + 3√3"))
+			Expect(f.Roots[1].Decimal).To(BeNumerically("~", 0.19615, 1e-5))
+			Expect(f.Roots[1].Surd).To(Equal(&api.SurdJSON{
+				Rational:    api.RationalJSON{Numerator: "-5", Denominator: "1"},
+				Coefficient: api.RationalJSON{Numerator: "3", Denominator: "1"},
+				Radicand:    "3",
+			}))
+		})
+		It("should reduce irrational roots over a single denominator", func() {
+			f := factor("2x^2 - 2x - 1")
+			Expect(f.Roots).To(HaveLen(2))
+			Expect(f.Roots[0].Exact).To(Equal("(1 - √3) / 2"))
+			Expect(f.Roots[1].Exact).To(Equal("(1 + √3) / 2"))
+		})
+	})
+
+	Describe("complex roots", func() {
+		factor := func(query string) *api.FactorJSON {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse(query)), &respJSON)).To(Succeed())
+			Expect(respJSON.Factored).NotTo(BeNil())
+			return &respJSON
+		}
+
+		It("should give complex roots in a ± bi form without including them as intercepts", func() {
+			f := factor("expr=" + url.QueryEscape("x^2 + x + 1"))
+			Expect(f.Factored.Intercepts).To(BeEmpty())
+			Expect(f.Factored.Roots).To(HaveLen(2))
+
+			Expect(f.Factored.Roots[0].Value).To(Equal("-0.5 - 0.86603i"))
+			Expect(f.Factored.Roots[0].Exact).To(Equal("-1/2 - (√3 / 2)i"))
+			Expect(f.Factored.Roots[0].Complex).To(BeTrue())
+			Expect(f.Factored.Roots[0].Decimal).To(Equal(-0.5))
+			Expect(f.Factored.Roots[0].Imaginary).To(BeNumerically("~", -0.86603, 1e-5))
+			Expect(f.Factored.Roots[1].Value).To(Equal("-0.5 + 0.86603i"))
+			Expect(f.Factored.Roots[1].Exact).To(Equal("-1/2 + (√3 / 2)i"))
+		})
+		DescribeTable("exact complex roots",
+			func(expr string, expression string, exact []string) {
+				f := factor("expr=" + url.QueryEscape(expr) + "&complex=true")
+				Expect(f.Factored.Expression).To(Equal(expression))
+
+				var actual []string
+				for _, v := range f.Factored.Roots {
+					actual = append(actual, v.Exact)
+				}
+				Expect(actual).To(Equal(exact))
+			},
+			Entry("with an integer imaginary part", "x^2 + 2x + 5", "(x + 1 + 2i)(x + 1 - 2i)", []string{"-1 - 2i", "-1 + 2i"}),
+			Entry("with a real part of 0", "x^2 + 1", "(x + i)(x - i)", []string{"-i", "i"}),
+			Entry("with a fractional imaginary part", "4x^2 + 9", "4(x + (3/2)i)(x - (3/2)i)", []string{"-(3/2)i", "(3/2)i"}),
+			Entry("with a fractional real part", "4x^2 + 4x + 5", "4(x + 1/2 + i)(x + 1/2 - i)", []string{"-1/2 - i", "-1/2 + i"}),
+			Entry("with an irrational imaginary part", "x^2 + x + 1", "(x + 1/2 + (√3 / 2)i)(x + 1/2 - (√3 / 2)i)", []string{"-1/2 - (√3 / 2)i", "-1/2 + (√3 / 2)i"}),
+		)
+		It("should only factor into complex linear factors when asked to", func() {
+			f := factor("expr=" + url.QueryEscape("x^3 + x"))
+			Expect(f.Result).To(Equal("partial"))
+			Expect(f.Factored.Expression).To(Equal("x(x^2 + 1)"))
+			Expect(f.Factored.Intercepts).To(Equal([]string{"0"}))
+
+			f = factor("expr=" + url.QueryEscape("x^3 + x") + "&complex=true")
+			Expect(f.Result).To(Equal("quadratic"))
+			Expect(f.Factored.Expression).To(Equal("x(x + i)(x - i)"))
+			Expect(f.Factored.Intercepts).To(Equal([]string{"0"}))
+		})
+		It("should reject a 'complex' parameter that isn't a boolean", func() {
+			p := getProblem("expr=x^2%2B1&complex=maybe")
+			Expect(p.Code).To(Equal(api.InvalidBoolean))
+			Expect(p.Param).To(Equal("complex"))
+		})
+	})
+
+	Describe("solving with radicals", func() {
+		It("should fully factor a cubic without rational roots", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("x^3 - 2")+"&mode=radical")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Result).To(Equal("radical"))
+			Expect(respJSON.Factored).NotTo(BeNil())
+			Expect(respJSON.Factored.Expression).To(Equal("(x - ∛2)(x - ((-1 - i√3) / 2)∛2)(x - ((-1 + i√3) / 2)∛2)"))
+			Expect(respJSON.Factored.Intercepts).To(Equal([]string{"1.25992"}))
+
+			var exact []string
+			for _, v := range respJSON.Factored.Roots {
+				exact = append(exact, v.Exact)
+			}
+			Expect(exact).To(ConsistOf("∛2", "((-1 + i√3) / 2)∛2", "((-1 - i√3) / 2)∛2"))
+		})
+		It("should not solve with radicals unless asked to", func() {
+			Expect(getResponse("expr=" + url.QueryEscape("x^3 - 2"))).To(MatchJSON(`{"result": "not"}`))
+			Expect(getResponse("expr=" + url.QueryEscape("x^3 - 2") + "&mode=exact")).To(MatchJSON(`{"result": "not"}`))
+		})
+		It("should reject an unknown mode", func() {
+			p := getProblem("expr=x^3-2&mode=magic")
+			Expect(p.Code).To(Equal(api.InvalidMode))
+			Expect(p.Detail).To(Equal("Parameter 'mode' must be one of 'exact', 'radical' or 'numeric'"))
+		})
+	})
+
+	Describe("solving numerically", func() {
+		It("should approximate every root of a quintic", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("x^5 - x - 1")+"&mode=numeric&precision=12")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Result).To(Equal("numeric"))
+			Expect(respJSON.Factored).NotTo(BeNil())
+			Expect(respJSON.Factored.Intercepts).To(Equal([]string{"1.167303978261"}))
+			Expect(respJSON.Factored.Roots).To(HaveLen(5))
+			for _, v := range respJSON.Factored.Roots {
+				Expect(v.Exact).To(BeEmpty())
+				Expect(v.Error).To(BeNumerically(">", 0))
+				Expect(v.Error).To(BeNumerically("<", 1e-11))
+			}
+			Expect(respJSON.Factored.Roots[4].Approximate).To(Equal("1.167303978261"))
+			Expect(respJSON.Factored.Roots[0].Approximate).To(Equal("-0.764884433601 - 0.352471546032i"))
+		})
+		It("should write approximated roots to the requested precision", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("x^5 - x - 1")+"&mode=numeric&precision=3")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Factored.Intercepts).To(Equal([]string{"1.167"}))
+			Expect(respJSON.Factored.Roots[0].Value).To(Equal("-0.765 - 0.352i"))
+			Expect(respJSON.Factored.Expression).To(ContainSubstring("(x - 1.167)"))
+		})
+		It("should only approximate the roots that can't be found exactly", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("(x - 2)(x^5 - x - 1)")+"&mode=numeric")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Result).To(Equal("numeric"))
+			Expect(respJSON.Factored.Intercepts).To(Equal([]string{"1.1673039783", "2"}))
+			Expect(respJSON.Factored.Roots[5].Exact).To(Equal("2"))
+			Expect(respJSON.Factored.Roots[5].Approximate).To(BeEmpty())
+		})
+		DescribeTable("rejecting an invalid precision",
+			func(precision string) {
+				p := getProblem("expr=x^5-x-1&mode=numeric&precision=" + precision)
+				Expect(p.Code).To(Equal(api.InvalidPrecision))
+				Expect(p.Detail).To(Equal("Parameter 'precision' must be an integer from 1 to 100"))
+			},
+			Entry("that isn't a number", "lots"),
+			Entry("that is too small", "0"),
+			Entry("that is too large", "101"),
+		)
+	})
+
+	Describe("showing the working", func() {
+		It("should include each step when asked to", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("x^2 + 7x + 10")+"&steps=true")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Steps).To(Equal([]api.StepJSON{{
+				Kind:  "grouping",
+				Text:  "Find two numbers that multiply to ac = 10 and add to b = 7, which are 5 and 2. Splitting the middle term and grouping, x^2 + 7x + 10 = x^2 + 5x + 2x + 10 = (x + 5)(x + 2)",
+				LaTeX: `Find two numbers that multiply to \(ac = 10\) and add to \(b = 7\), which are \(5\) and \(2\). Splitting the middle term and grouping, \(x^{2} + 7x + 10 = x^{2} + 5x + 2x + 10 = (x + 5)(x + 2)\)`,
+			}}))
+		})
+		It("should explain why a polynomial can't be factored", func() {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape("x^3 + 2x + 1")+"&steps=true")), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Result).To(Equal("not"))
+			Expect(respJSON.Steps).To(HaveLen(2))
+			Expect(respJSON.Steps[1].Kind).To(Equal("irreducible"))
+		})
+		It("should leave the steps out unless asked for them", func() {
+			Expect(getResponse("expr=" + url.QueryEscape("x^2 + 7x + 10"))).NotTo(ContainSubstring(`"steps"`))
+			Expect(getResponse("expr=" + url.QueryEscape("x^2 + 7x + 10") + "&steps=false")).NotTo(ContainSubstring(`"steps"`))
+		})
+		It("should reject an invalid value", func() {
+			p := getProblem("expr=x^2-1&steps=please")
+			Expect(p.Code).To(Equal(api.InvalidBoolean))
+			Expect(p.Param).To(Equal("steps"))
+		})
+	})
+
+	DescribeTable("reporting special products",
+		func(expr, identity, expression string) {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse("expr="+url.QueryEscape(expr))), &respJSON)).To(Succeed())
+
+			Expect(respJSON.Factored).NotTo(BeNil())
+			Expect(respJSON.Factored.Identity).To(Equal(identity))
+			Expect(respJSON.Factored.Expression).To(Equal(expression))
 		},
-		Entry("should throw an error when the 'degree' query isn't present", ""),
-		Entry("should throw an error when the 'degree' query isn't numeric", "notanumber"),
-		Entry("should throw an error when the 'degree' query isn't an integer", "3.14"),
-		Entry("should throw an error when the 'degree' query is < 2", "1"),
+		Entry("for a difference of squares", "4x^2 - 9", "difference of squares", "(2x + 3)(2x - 3)"),
+		Entry("for a perfect square trinomial", "x^2 - 6x + 9", "perfect square", "(x - 3)^2"),
+		Entry("for a binomial expansion", "x^3 + 3x^2 + 3x + 1", "binomial expansion", "(x + 1)^3"),
+		Entry("for a polynomial that isn't a special product", "x^2 + 7x + 10", "", "(x + 5)(x + 2)"),
+	)
+
+	Describe("writing the result in other formats", func() {
+		factor := func(query string) *api.FactoredJSON {
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(getResponse(query)), &respJSON)).To(Succeed())
+			Expect(respJSON.Factored).NotTo(BeNil())
+			return respJSON.Factored
+		}
+
+		DescribeTable("the expression and exact roots",
+			func(expr, format, expression string, exact []string) {
+				f := factor("expr=" + url.QueryEscape(expr) + "&format=" + format)
+				Expect(f.Expression).To(Equal(expression))
+
+				var actual []string
+				for _, v := range f.Roots {
+					actual = append(actual, v.Exact)
+				}
+				Expect(actual).To(Equal(exact))
+			},
+			Entry("as text by default", "2x^2 - 2x - 1", "", "2(x - (1 - √3) / 2)(x - (1 + √3) / 2)", []string{"(1 - √3) / 2", "(1 + √3) / 2"}),
+			Entry("as LaTeX", "2x^2 - 2x - 1", "latex", `2(x - \frac{1 - \sqrt{3}}{2})(x - \frac{1 + \sqrt{3}}{2})`, []string{`\frac{1 - \sqrt{3}}{2}`, `\frac{1 + \sqrt{3}}{2}`}),
+			Entry("as ASCII", "2x^2 - 2x - 1", "ascii", "2(x - (1 - sqrt(3)) / 2)(x - (1 + sqrt(3)) / 2)", []string{"(1 - sqrt(3)) / 2", "(1 + sqrt(3)) / 2"}),
+			Entry("as LaTeX with a power of x", "x^12 - x^10", "latex", "x^{10}(x + 1)(x - 1)", []string{"-1", "0", "1"}),
+			Entry("as LaTeX with complex roots", "x^2 + x + 1", "latex", "x^{2} + x + 1", []string{`-\frac{1}{2} - \frac{\sqrt{3}}{2}i`, `-\frac{1}{2} + \frac{\sqrt{3}}{2}i`}),
+			Entry("as LaTeX with a repeated factor", "-6(x - 1)^2(x + 2)", "latex", "-6(x + 2)(x - 1)^{2}", []string{"-2", "1"}),
+		)
+		It("should write radicals in every format", func() {
+			f := factor("expr=" + url.QueryEscape("x^3 - 2") + "&mode=radical&format=ascii")
+			Expect(f.Roots[len(f.Roots)-1].Exact).To(Equal("cbrt(2)"))
+			f = factor("expr=" + url.QueryEscape("x^3 - 2") + "&mode=radical&format=latex")
+			Expect(f.Roots[len(f.Roots)-1].Exact).To(Equal(`\sqrt[3]{2}`))
+		})
+		It("should write complex factors exactly", func() {
+			f := factor("expr=" + url.QueryEscape("x^2 + x + 1") + "&complex=true&format=latex")
+			Expect(f.Expression).To(Equal(`(x + \frac{1}{2} + \frac{\sqrt{3}}{2}i)(x + \frac{1}{2} - \frac{\sqrt{3}}{2}i)`))
+		})
+		It("should write presentation MathML", func() {
+			f := factor("expr=" + url.QueryEscape("x^2 - 1") + "&format=mathml")
+			Expect(f.Expression).To(HavePrefix(`<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow>`))
+			Expect(f.Expression).To(ContainSubstring(`<mi>x</mi><mo>+</mo><mn>1</mn>`))
+			Expect(f.Roots[0].Exact).To(Equal(`<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mo>-</mo><mn>1</mn></mrow></math>`))
+		})
+		It("should leave decimal values alone", func() {
+			f := factor("expr=" + url.QueryEscape("x^2 + x + 1") + "&format=ascii")
+			Expect(f.Roots[0].Value).To(Equal("-0.5 - 0.86603i"))
+		})
+		It("should reject an unknown format", func() {
+			p := getProblem("expr=x^2-1&format=html")
+			Expect(p.Code).To(Equal(api.InvalidFormat))
+			Expect(p.Detail).To(Equal("Parameter 'format' must be one of 'text', 'ascii', 'latex' or 'mathml'"))
+		})
+	})
+
+	It("should accept parameters in the body of a POST request", func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "https://example.com", strings.NewReader("expr="+url.QueryEscape("x² + 7x + 10")))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		api.Factor(w, r)
+
+		var respJSON api.FactorJSON
+		Expect(json.NewDecoder(w.Result().Body).Decode(&respJSON)).To(Succeed())
+		Expect(respJSON.Factored).NotTo(BeNil())
+		Expect(respJSON.Factored.Intercepts).To(Equal([]string{"-5", "-2"}))
+	})
+
+	Describe("when the request's context is done", func() {
+		It("should time out once the deadline has passed", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+			defer cancel()
+			w := httptest.NewRecorder()
+			api.Factor(w, httptest.NewRequest("", "https://example.com?expr=x%5E3-6x%5E2%2B11x-6", nil).WithContext(ctx))
+
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(readProblem(w).Code).To(Equal(api.Timeout))
+		})
+		It("should time out soon after the deadline while factoring", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			w := httptest.NewRecorder()
+			start := time.Now()
+			api.Factor(w, httptest.NewRequest("", "https://example.com?expr="+url.QueryEscape("x^1000 - 2"), nil).WithContext(ctx))
+
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(readProblem(w).Code).To(Equal(api.Timeout))
+		})
+		It("should not write anything once the client has gone away", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			w := httptest.NewRecorder()
+			api.Factor(w, httptest.NewRequest("", "https://example.com?expr=x%5E3-6x%5E2%2B11x-6", nil).WithContext(ctx))
+
+			Expect(w.Body.Len()).To(BeZero())
+		})
+	})
+
+	DescribeTable("when coefficients must be handled exactly",
+		func(queries string, expected *api.FactorJSON) {
+			resp := getResponse(queries)
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+			var respJSON api.FactorJSON
+			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
+
+			Expect(basic(respJSON)).To(Equal(*expected))
+		},
+		Entry("should find a rational root of '3x^2 - 10x + 7'",
+			"degree=2&x^0=7&x^1=-10&x^2=3",
+			&api.FactorJSON{
+				Result: "full",
+				Factored: &api.FactoredJSON{
+					Expression: "(x - 1)(3x - 7)",
+					Intercepts: []string{"1", "2.33333"},
+				},
+			},
+		),
+		Entry("should factor a polynomial with fractional coefficients",
+			"degree=3&x^0=-0.5&x^1=2.5&x^2=-3.5&x^3=1.5",
+			&api.FactorJSON{
+				Result: "full",
+				Factored: &api.FactoredJSON{
+					Expression: "(1/2)(3x - 1)(x - 1)^2",
+					Intercepts: []string{"0.33333", "1"},
+				},
+			},
+		),
+		Entry("should keep a fractional constant factor exact",
+			"degree=2&x^1=-1/3&x^2=2",
+			&api.FactorJSON{
+				Result: "full",
+				Factored: &api.FactoredJSON{
+					Expression: "(1/3)x(6x - 1)",
+					Intercepts: []string{"0", "0.16667"},
+				},
+			},
+		),
+		Entry("should not lose precision with very large coefficients",
+			"degree=3&x^0=-12345678901234567&x^1=12345678901234568&x^2=-12345678901234568&x^3=12345678901234567",
+			&api.FactorJSON{
+				Result: "partial",
+				Factored: &api.FactoredJSON{
+					Expression: "(12345678901234567x^2 - x + 12345678901234567)(x - 1)",
+					Intercepts: []string{"1"},
+				},
+			},
+		),
 	)
 
 	DescribeTable("the results when attempting to factor certain polynomials",
@@ -52,12 +603,12 @@ var _ = Describe("the Factor function", func() {
 			}
 
 			resp := getResponse(queries)
-			Expect(resp).NotTo(ContainSubstring("ERROR:"))
+			Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
 
 			var respJSON api.FactorJSON
 			Expect(json.Unmarshal([]byte(resp), &respJSON)).To(Succeed())
 
-			Expect(respJSON).To(Equal(*expected(intercepts)))
+			Expect(basic(respJSON)).To(Equal(*expected(intercepts)))
 		},
 		Entry("should factor 'x^2 + 7x + 10' into '(x - 2)(x - 5)'",
 			func() ([]float64, []string) {
@@ -74,17 +625,17 @@ var _ = Describe("the Factor function", func() {
 				}
 			},
 		),
-		Entry("should factor '3x^2 - 12' into '(x + 6)(x - 6)'",
+		Entry("should factor '3x^2 - 12' into '3(x + 2)(x - 2)'",
 			func() ([]float64, []string) {
 				// No intercept array is returned because its not needed
-				return []float64{3, 0, -12}, nil
+				return []float64{-12, 0, 3}, nil
 			},
 			func(_ []string) *api.FactorJSON {
 				return &api.FactorJSON{
 					Result: "full",
 					Factored: &api.FactoredJSON{
-						Expression: "(x + 6)(x - 6)",
-						Intercepts: []string{"6", "-6"},
+						Expression: "3(x + 2)(x - 2)",
+						Intercepts: []string{"-2", "2"},
 					},
 				}
 			},
@@ -117,74 +668,22 @@ var _ = Describe("the Factor function", func() {
 				return &api.FactorJSON{
 					Result: "quadratic",
 					Factored: &api.FactoredJSON{
-						Expression: "(x + 10.19615)(x - 0.19615)",
+						Expression: "(x + 5 + 3√3)(x + 5 - 3√3)",
 						Intercepts: []string{"-10.19615", "0.19615"},
 					},
 				}
 			},
 		),
-		Entry("should factor into (-b ± √(b²-4ac)) ÷ 2a) form when the discriminant is negative",
+		Entry("should leave a quadratic whole when the discriminant is negative",
 			func() ([]float64, []string) {
-				// Randomly generate coefficients
-				coefficients := make([]float64, 3)
-			generate:
-				for i := 0; i < 5; i++ {
-					for i := range coefficients {
-						coefficients[i] = float64(rand.Intn(1999)+1) / 100
-					}
-
-					// Check if b² is larger than or equal to 4ac. If it is, restart the loop.
-					if math.Pow(coefficients[1], 2) >= (4 * coefficients[0] * coefficients[2]) {
-						coefficients = make([]float64, 3)
-						continue generate
-					}
-
-					// Make sure that a trinomial with these coefficients cannot be factored by grouping
-					for _, v := range coefficients {
-						// If any of the coefficients are not whole numbers, the whole thing can't be factored by grouping
-						if v != math.Round(v) {
-							break generate
-						}
-					}
-
-					// If the loop is still running, all coefficients must be whole numbers
-					// Now, check if the trinomial can be factored by grouping
-					var (
-						product = coefficients[0] * coefficients[2]
-						half    = math.Ceil(coefficients[1] / 2)
-					)
-					for a, b := 1., coefficients[1]-1; a < half; a, b = a+1, b-1 {
-						if a*b == product {
-							// If the trinomial is factorable by grouping, reset the array and restart the loop
-							coefficients = make([]float64, 3)
-							continue generate
-						}
-					}
-				}
-
-				// If coefficients[0] is 0, then everything must be 0 (because 0 cannot be randomly generated)
-				if coefficients[0] == 0 {
-					Skip("took too long to generate coefficients")
-					return nil, nil
-				}
-
-				ints := make([]string, 2)
-				for i, v := range []byte{'+', '-'} {
-					var parts [3]string
-					for i, v := range []float64{coefficients[1] * -1, math.Pow(coefficients[1], 2) - (4 * coefficients[2] * coefficients[0]), 2 * coefficients[2]} {
-						parts[i] = strings.TrimRight(strings.TrimRight(strconv.FormatFloat(v, 'f', 5, 64), "0"), ".")
-					}
-					ints[i] = fmt.Sprintf("(%s %c √(%s)) / %s", parts[0], v, parts[1], parts[2])
-				}
-
-				return coefficients, ints
+				// No intercept array is returned because complex roots aren't intercepts
+				return []float64{5, 2, 1}, nil
 			},
-			func(ints []string) *api.FactorJSON {
+			func(_ []string) *api.FactorJSON {
 				return &api.FactorJSON{
-					Result: "quadratic",
+					Result: "not",
 					Factored: &api.FactoredJSON{
-						Expression: fmt.Sprintf("(%s)(%s)", ints[0], ints[1]),
-						Intercepts: ints,
+						Expression: "x^2 + 2x + 5",
 					},
 				}
 			},
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(Endpoint{
+		Name:   "v1/factor",
+		Method: http.MethodPost,
+		Params: []Param{
+			{Name: "expression", In: "body", Type: "string", Example: "x^3 - 2x + 1", Description: "The polynomial as a free-form expression; exactly one of this and 'coefficients' is required"},
+			{Name: "coefficients", In: "body", Type: "array", Example: []interface{}{-1, 0, "1/2"}, Description: "The coefficients of the polynomial starting with the constant term, each a number or a string like \"-3/4\""},
+			{Name: "domain", In: "body", Type: "string", Default: "real", Enum: []string{"real", "complex"}, Description: "The numbers to factor over"},
+			{Name: "mode", In: "body", Type: "string", Default: "exact", Enum: []string{"exact", "radical", "numeric"}, Description: "How far to go when there are no more rational roots"},
+			{Name: "precision", In: "body", Type: "integer", Default: defaultDigits, Example: 12, Description: fmt.Sprintf("The number of decimal digits to approximate roots to, from 1 to %d; only allowed in numeric mode", poly.MaxDigits)},
+			{Name: "format", In: "body", Type: "string", Default: string(expr.Text), Enum: []string{string(expr.Text), string(expr.ASCII), string(expr.LaTeX), string(expr.MathML)}, Description: "How to write the expression and exact roots"},
+			{Name: "steps", In: "body", Type: "boolean", Default: false, Description: "Whether to include the working"},
+		},
+		MediaTypes:  []string{"application/json"},
+		Description: "Factor a polynomial given in a strictly validated JSON request body.",
+		Handler:     FactorV1,
+		Request:     FactorRequestV1{},
+		Response:    FactorJSON{},
+	})
+}
+
+// The largest request body, in bytes, that the JSON endpoints accept.
+const maxBodySize = 1 << 20
+
+// Struct defining the JSON request body of version 1 of the factor endpoint. Exactly one of Expression and Coefficients
+// must be given, and every other field is optional.
+type FactorRequestV1 struct {
+	Expression   string            `json:"expression,omitempty"`   // The polynomial as a free-form expression, like "x^3 - 2x + 1"
+	Coefficients []json.RawMessage `json:"coefficients,omitempty"` // The coefficients starting with the constant term, each a number or a string like "-3/4"
+	Domain       string            `json:"domain,omitempty"`       // Either "real", which is the default, or "complex"
+	Mode         string            `json:"mode,omitempty"`         // Either "exact", which is the default, "radical" or "numeric"
+	Precision    *int              `json:"precision,omitempty"`    // The number of decimal digits in numeric mode, which is 10 by default
+	Format       string            `json:"format,omitempty"`       // Either "text", which is the default, "ascii", "latex" or "mathml"
+	Steps        bool              `json:"steps,omitempty"`        // Whether to include the working
+}
+
+// API function for factoring polynomials, which takes a FactorRequestV1 as a JSON body in a POST request and responds
+// with the same FactorJSON as Factor. Unlike Factor, the request is validated strictly: unknown fields, fields of the
+// wrong type, trailing data and options that don't apply are all rejected.
+func FactorV1(w http.ResponseWriter, r *http.Request) {
+	_, b, ok := readBody(w, r, "application/json")
+	if !ok {
+		return
+	}
+
+	p, opts, problem := parseFactorRequestV1(b)
+	if problem != nil {
+		writeProblem(w, problem)
+		return
+	}
+	writeFactorization(r.Context(), w, p, opts)
+}
+
+// Read the body of a POST request, which must have one of the given media types and be no larger than maxBodySize.
+// Returns the media type of the body, or writes an error and returns false if the request can't be accepted.
+func readBody(w http.ResponseWriter, r *http.Request, types ...string) (string, []byte, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, newProblem(MethodNotAllowed, "", "Method must be POST"))
+		return "", nil, false
+	}
+
+	t, _, e := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	accepted := false
+	for _, v := range types {
+		accepted = accepted || (e == nil && t == v)
+	}
+	if !accepted {
+		writeProblem(w, newProblem(UnsupportedMediaType, "", "Content-Type must be %s", strings.Join(types, " or ")))
+		return "", nil, false
+	}
+
+	// Reading one byte more than the limit shows whether the body is too large
+	b, e := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if e != nil {
+		writeProblem(w, newProblem(InvalidBody, "", "Could not read request body"))
+		return "", nil, false
+	} else if len(b) > maxBodySize {
+		writeProblem(w, newProblem(BodyTooLarge, "", "Request body must not be larger than %d bytes", maxBodySize))
+		return "", nil, false
+	}
+
+	return t, b, true
+}
+
+// Parse a FactorRequestV1 into the polynomial to factor and the options to factor it with, returning a problem if it is
+// not valid.
+func parseFactorRequestV1(b []byte) (poly.Polynomial, factorOptions, *ProblemJSON) {
+	req, problem := decodeFactorRequestV1(b)
+	if problem != nil {
+		return nil, factorOptions{}, problem
+	}
+
+	var p poly.Polynomial
+	if req.Expression != "" {
+		p, problem = parseExpression(req.Expression, "Field", "expression")
+	} else {
+		p, problem = parseCoefficientsJSON(req.Coefficients)
+	}
+	if problem != nil {
+		return nil, factorOptions{}, problem
+	}
+
+	opts, problem := req.options()
+	return p, opts, problem
+}
+
+// Decode a request body that must hold a single FactorRequestV1 and nothing else, returning a problem if it doesn't.
+func decodeFactorRequestV1(b []byte) (FactorRequestV1, *ProblemJSON) {
+	var req FactorRequestV1
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if e := d.Decode(&req); e != nil {
+		switch e := e.(type) {
+		case *json.SyntaxError:
+			return req, newProblem(InvalidBody, "", "Could not parse request body at byte %d: %s", e.Offset, e.Error())
+		case *json.UnmarshalTypeError:
+			if e.Field == "" {
+				return req, newProblem(InvalidBody, "", "Request body must be a JSON object")
+			}
+			return req, newProblem(InvalidType, e.Field, "Field '%s' must not be a JSON %s", e.Field, e.Value)
+		}
+		if e == io.EOF {
+			return req, newProblem(InvalidBody, "", "Request body must not be empty")
+		}
+		if field := unknownField(e); field != "" {
+			return req, newProblem(UnknownField, field, "Field '%s' is not allowed", field)
+		}
+		return req, newProblem(InvalidBody, "", "Could not parse request body: %s", e.Error())
+	} else if d.Decode(&struct{}{}) != io.EOF {
+		return req, newProblem(InvalidBody, "", "Request body must contain a single JSON object")
+	}
+
+	switch {
+	case req.Expression == "" && req.Coefficients == nil:
+		return req, newProblem(MissingPolynomial, "", "Exactly one of the fields 'expression' and 'coefficients' must be given")
+	case req.Expression != "" && req.Coefficients != nil:
+		return req, newProblem(ConflictingPolynomial, "", "Exactly one of the fields 'expression' and 'coefficients' must be given")
+	}
+
+	return req, nil
+}
+
+// Find the name of the field in an error from a decoder that disallows unknown fields, or return an empty string if the
+// error isn't about an unknown field. The decoder has no type for the error, so its message is the only way to tell.
+//  json: unknown field "domian" -> domian
+func unknownField(e error) string {
+	const prefix = "json: unknown field "
+	if s := e.Error(); strings.HasPrefix(s, prefix) {
+		if field, err := strconv.Unquote(strings.TrimPrefix(s, prefix)); err == nil {
+			return field
+		}
+	}
+
+	return ""
+}
+
+// Parse the coefficients of a polynomial given as a JSON array, returning a problem if they are not valid. Each
+// coefficient is either a JSON number or a string holding a number or a fraction.
+//  parseCoefficientsJSON([-1, 0, "1/2"]) -> (1/2)x^2 - 1
+func parseCoefficientsJSON(coefficients []json.RawMessage) (poly.Polynomial, *ProblemJSON) {
+	if len(coefficients) < 3 {
+		return nil, newProblem(DegreeTooLow, "coefficients", "Field 'coefficients' must have at least 3 elements, for a degree >= 2")
+	} else if len(coefficients)-1 > poly.MaxParseDegree {
+		return nil, newProblem(DegreeTooHigh, "coefficients", "Field 'coefficients' must have at most %d elements, for a degree <= %d", poly.MaxParseDegree+1, poly.MaxParseDegree)
+	}
+
+	p := make(poly.Polynomial, len(coefficients))
+	for i, v := range coefficients {
+		param := fmt.Sprintf("coefficients[%d]", i)
+
+		// A string holding a number is taken as it is, and so is a number, once it is known to be one
+		var s string
+		if json.Unmarshal(v, &s) != nil {
+			var n json.Number
+			if json.Unmarshal(v, &n) != nil {
+				return nil, newProblem(InvalidCoefficient, param, "Element %d of field 'coefficients' must be a number or a string", i)
+			}
+			s = n.String()
+		}
+
+		if r, ok := new(big.Rat).SetString(strings.TrimSpace(s)); !ok {
+			return nil, newProblem(InvalidCoefficient, param, "Could not parse element %d of field 'coefficients'", i)
+		} else {
+			p[i] = r
+		}
+	}
+	if p[len(p)-1].Sign() == 0 {
+		return nil, newProblem(ZeroLeadingCoefficient, fmt.Sprintf("coefficients[%d]", len(p)-1), "The last element of field 'coefficients' must not be 0")
+	}
+
+	return p, nil
+}
+
+// Convert the options in the request into the options for factoring, returning a problem if they are not valid.
+func (req FactorRequestV1) options() (factorOptions, *ProblemJSON) {
+	opts := factorOptions{mode: req.Mode, digits: defaultDigits, steps: req.Steps, format: expr.Format(req.Format)}
+
+	switch req.Domain {
+	case "", "real":
+	case "complex":
+		opts.complex = true
+	default:
+		return opts, newProblem(InvalidDomain, "domain", "Field 'domain' must be either 'real' or 'complex'")
+	}
+
+	if opts.format == "" {
+		opts.format = expr.Text
+	}
+
+	if req.Precision != nil {
+		if req.Mode != "numeric" {
+			return opts, newProblem(UnexpectedPrecision, "precision", "Field 'precision' can only be given in numeric mode")
+		}
+		opts.digits = *req.Precision
+	}
+
+	return opts, opts.check("Field")
+}
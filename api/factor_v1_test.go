@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+var _ = Describe("the FactorV1 function", func() {
+	post := func(body string) *http.Response {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "https://example.com", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+
+		api.FactorV1(w, r)
+		return w.Result()
+	}
+	getResponse := func(body string) string {
+		b, e := ioutil.ReadAll(post(body).Body)
+		Expect(e).NotTo(HaveOccurred())
+		return string(b)
+	}
+	factor := func(body string) api.FactorJSON {
+		var respJSON api.FactorJSON
+		Expect(json.Unmarshal([]byte(getResponse(body)), &respJSON)).To(Succeed())
+		return respJSON
+	}
+
+	It("should factor a polynomial given by its coefficients", func() {
+		f := factor(`{"coefficients": [10, "7", 1]}`)
+		Expect(f.Result).To(Equal("full"))
+		Expect(f.Factored.Expression).To(Equal("(x + 5)(x + 2)"))
+	})
+	It("should accept fractions and decimals as coefficients", func() {
+		f := factor(`{"coefficients": ["-1/4", 0, 1.0]}`)
+		Expect(f.Factored.Expression).To(Equal("(1/4)(2x + 1)(2x - 1)"))
+	})
+	It("should factor a polynomial given as an expression", func() {
+		f := factor(`{"expression": "x^2 + 1", "domain": "complex", "format": "latex"}`)
+		Expect(f.Result).To(Equal("quadratic"))
+		Expect(f.Factored.Expression).To(Equal("(x + i)(x - i)"))
+	})
+	It("should give the same response as the GET endpoint", func() {
+		w := httptest.NewRecorder()
+		api.Factor(w, httptest.NewRequest("", "https://example.com?expr="+url.QueryEscape("x^5 - x - 1")+"&mode=numeric&precision=6&steps=true", nil))
+		get, e := ioutil.ReadAll(w.Result().Body)
+		Expect(e).NotTo(HaveOccurred())
+
+		Expect(getResponse(`{"expression": "x^5 - x - 1", "mode": "numeric", "precision": 6, "steps": true}`)).To(Equal(string(get)))
+	})
+	It("should respond with JSON", func() {
+		Expect(post(`{"coefficients": [1, 2, 1]}`).Header.Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	DescribeTable("rejecting an invalid request body",
+		func(body string, code api.ProblemCode, param, detail string) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "https://example.com", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			api.FactorV1(w, r)
+
+			p := readProblem(w)
+			Expect(p.Code).To(Equal(code))
+			Expect(p.Param).To(Equal(param))
+			Expect(p.Detail).To(Equal(detail))
+		},
+		Entry("when it is empty", ``, api.InvalidBody, "", "Request body must not be empty"),
+		Entry("when it isn't JSON", `{"expression": x}`, api.InvalidBody, "", "Could not parse request body at byte 16: invalid character 'x' looking for beginning of value"),
+		Entry("when it isn't an object", `["x^2"]`, api.InvalidBody, "", "Request body must be a JSON object"),
+		Entry("when there is more than one object", `{"expression": "x^2"} {}`, api.InvalidBody, "", "Request body must contain a single JSON object"),
+		Entry("when a field is unknown", `{"expr": "x^2"}`, api.UnknownField, "expr", "Field 'expr' is not allowed"),
+		Entry("when a field has the wrong type", `{"expression": "x^2", "steps": "yes"}`, api.InvalidType, "steps", "Field 'steps' must not be a JSON string"),
+		Entry("when there is no polynomial", `{"mode": "radical"}`, api.MissingPolynomial, "", "Exactly one of the fields 'expression' and 'coefficients' must be given"),
+		Entry("when there are two polynomials", `{"expression": "x^2", "coefficients": [0, 0, 1]}`, api.ConflictingPolynomial, "", "Exactly one of the fields 'expression' and 'coefficients' must be given"),
+		Entry("when the expression is invalid", `{"expression": "x^2 +"}`, api.InvalidExpression, "expression", "Could not parse field 'expression' at column 6: expected a number, x or \"(\" but found \"end of expression\""),
+		Entry("when the degree is too low", `{"expression": "x + 1"}`, api.DegreeTooLow, "expression", "Field 'expression' must have a degree >= 2"),
+		Entry("when there are too few coefficients", `{"coefficients": [1, 1]}`, api.DegreeTooLow, "coefficients", "Field 'coefficients' must have at least 3 elements, for a degree >= 2"),
+		Entry("when there are too many coefficients", `{"coefficients": [1`+strings.Repeat(", 0", poly.MaxParseDegree)+`, 1]}`, api.DegreeTooHigh, "coefficients", fmt.Sprintf("Field 'coefficients' must have at most %d elements, for a degree <= %d", poly.MaxParseDegree+1, poly.MaxParseDegree)),
+		Entry("when a coefficient isn't a number", `{"coefficients": [1, true, 1]}`, api.InvalidCoefficient, "coefficients[1]", "Element 1 of field 'coefficients' must be a number or a string"),
+		Entry("when a coefficient can't be parsed", `{"coefficients": [1, "one", 1]}`, api.InvalidCoefficient, "coefficients[1]", "Could not parse element 1 of field 'coefficients'"),
+		Entry("when the leading coefficient is 0", `{"coefficients": [1, 0, 1, 0]}`, api.ZeroLeadingCoefficient, "coefficients[3]", "The last element of field 'coefficients' must not be 0"),
+		Entry("when the domain is unknown", `{"expression": "x^2", "domain": "quaternion"}`, api.InvalidDomain, "domain", "Field 'domain' must be either 'real' or 'complex'"),
+		Entry("when the mode is unknown", `{"expression": "x^2", "mode": "magic"}`, api.InvalidMode, "mode", "Field 'mode' must be one of 'exact', 'radical' or 'numeric'"),
+		Entry("when the format is unknown", `{"expression": "x^2", "format": "html"}`, api.InvalidFormat, "format", "Field 'format' must be one of 'text', 'ascii', 'latex' or 'mathml'"),
+		Entry("when the precision is out of range", `{"expression": "x^2", "mode": "numeric", "precision": 0}`, api.InvalidPrecision, "precision", "Field 'precision' must be an integer from 1 to 100"),
+		Entry("when the precision doesn't apply", `{"expression": "x^2", "precision": 5}`, api.UnexpectedPrecision, "precision", "Field 'precision' can only be given in numeric mode"),
+	)
+
+	It("should only accept POST requests", func() {
+		w := httptest.NewRecorder()
+		api.FactorV1(w, httptest.NewRequest("GET", "https://example.com", nil))
+		Expect(readProblem(w).Code).To(Equal(api.MethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("POST"))
+	})
+	It("should only accept JSON", func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "https://example.com", strings.NewReader("expr=x^2"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		api.FactorV1(w, r)
+		Expect(readProblem(w).Code).To(Equal(api.UnsupportedMediaType))
+	})
+	It("should reject a body that is too large", func() {
+		resp := post(`{"expression": "x^2` + strings.Repeat(" ", 1<<20) + `"}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+})
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Convert a factorization into the JSON response, formatting the values of roots as decimals with the given number of
+// decimal places. The expression and the exact roots are written in the given format, with roots that were only
+// approximated written as decimals.
+func newFactorJSON(f poly.Factorization, format expr.Format, digits int) *FactorJSON {
+	// A quadratic that doesn't factor over the real numbers still has its complex roots shown
+	if f.Result == poly.Not && len(f.ComplexRoots()) == 0 {
+		return &FactorJSON{Result: string(f.Result)}
+	}
+
+	var factors []ComponentJSON
+	for _, v := range f.Factors {
+		c := ComponentJSON{Multiplicity: v.Multiplicity, Degree: v.Polynomial.Degree(), Irreducible: v.Irreducible}
+		for _, a := range v.Polynomial {
+			c.Coefficients = append(c.Coefficients, json.Number(a.RatString()))
+		}
+		factors = append(factors, c)
+	}
+	constantJSON := newRationalJSON(f.Constant)
+
+	// A linear factor is shown for each root, sorted by multiplicity and then by the root. Linear factors of the
+	// polynomial are shown as they are, but factors that had their roots found with the quadratic formula are shown as
+	// (x - root), so their leading coefficients have to be moved into the constant factor. Quadratics with complex roots
+	// are only split into complex linear factors when factoring over the complex numbers.
+	type linearFactor struct {
+		root         *big.Rat
+		multiplicity int
+		expr         expr.Node
+	}
+	var (
+		constant       = new(big.Rat).Set(f.Constant)
+		x              = expr.Var("x")
+		power          []expr.Node // Either empty or a power of x
+		rest           []expr.Node
+		linear         []linearFactor
+		complexFactors []expr.Node
+		split          = f.Complex
+	)
+	for _, v := range f.Factors {
+		switch {
+		case v.Polynomial.Degree() == 1 && v.Roots[0].Value.Sign() == 0:
+			power = []expr.Node{expr.Pow(x, v.Multiplicity)}
+		case v.Polynomial.Degree() == 1:
+			linear = append(linear, linearFactor{v.Roots[0].Value, v.Multiplicity, expr.Pow(v.Polynomial.Expr("x"), v.Multiplicity)})
+		case len(v.Roots) > 0 || (len(v.Complex) > 0 && split):
+			for i := 0; i < v.Multiplicity; i++ {
+				constant.Mul(constant, v.Polynomial.Leading())
+			}
+			for _, r := range v.Roots {
+				linear = append(linear, linearFactor{r.Value, v.Multiplicity, expr.Pow(rootFactor(r, digits), v.Multiplicity)})
+			}
+			for _, r := range sortComplex(v.Complex) {
+				complexFactors = append(complexFactors, expr.Pow(complexFactor(r, digits), v.Multiplicity))
+			}
+		default:
+			// Factors that couldn't be factored any further come before the linear factors
+			rest = append(rest, expr.Pow(v.Polynomial.Expr("x"), v.Multiplicity))
+		}
+	}
+	sort.SliceStable(linear, func(i, j int) bool {
+		if linear[i].multiplicity != linear[j].multiplicity {
+			return linear[i].multiplicity < linear[j].multiplicity
+		}
+		return linear[i].root.Cmp(linear[j].root) < 0
+	})
+
+	// A constant of 1 is implied, and a constant of -1 is just a minus sign
+	product := []expr.Node{expr.Rat(new(big.Rat).Abs(constant))}
+	product = append(append(product, power...), rest...)
+	for _, v := range linear {
+		product = append(product, v.expr)
+	}
+	product = append(product, complexFactors...)
+	expression := expr.Product(product...)
+	if constant.Sign() < 0 {
+		expression = expr.Neg(expression)
+	}
+
+	var (
+		intercepts []string
+		roots      []RootJSON
+	)
+	for _, c := range f.Factors {
+		for _, v := range c.Roots {
+			r := RootJSON{Value: formatRat(v.Value, digits), Multiplicity: v.Multiplicity}
+			r.Decimal, _ = v.Value.Float64()
+			if v.Exact {
+				r.Exact = format.Write(expr.Rat(v.Value))
+				r.Numerator = json.Number(v.Value.Num().String())
+				r.Denominator = json.Number(v.Value.Denom().String())
+			} else if v.Radical != nil {
+				r.Exact = format.Write(*v.Radical)
+			} else if v.Bound != nil {
+				r.Approximate = formatDecimal(v.Value)
+				r.Error, _ = v.Bound.Float64()
+			} else {
+				r.Exact = format.Write(v.Surd.Expr())
+				r.Surd = newSurdJSON(*v.Surd)
+			}
+
+			roots = append(roots, r)
+		}
+
+		// Complex roots aren't intercepts, so they are only included in the list of roots
+		for _, v := range c.Complex {
+			r := RootJSON{Value: formatComplex(v.Value, v.Imag, digits), Multiplicity: v.Multiplicity, Complex: true}
+			r.Decimal, _ = v.Value.Float64()
+			r.Imaginary, _ = v.Imag.Float64()
+			if v.Bound != nil {
+				r.Approximate = joinComplex(formatDecimal(v.Value), v.Value.Sign(), formatDecimal(new(big.Rat).Abs(v.Imag)), v.Imag.Sign())
+				r.Error, _ = v.Bound.Float64()
+			} else {
+				r.Exact = format.Write(exactComplexRoot(v))
+			}
+			if v.Surd != nil {
+				r.Surd = newSurdJSON(*v.Surd)
+			}
+
+			roots = append(roots, r)
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		if roots[i].Decimal != roots[j].Decimal {
+			return roots[i].Decimal < roots[j].Decimal
+		}
+		return roots[i].Imaginary < roots[j].Imaginary
+	})
+	for _, v := range roots {
+		if !v.Complex {
+			intercepts = append(intercepts, v.Value)
+		}
+	}
+
+	return &FactorJSON{
+		Result: string(f.Result),
+		Factored: &FactoredJSON{
+			Expression: format.Write(expression),
+			Intercepts: intercepts,
+			Roots:      roots,
+			Constant:   &constantJSON,
+			Identity:   string(f.Identity),
+			Factors:    factors,
+		},
+	}
+}
+
+// Convert the working of a factorization into its JSON representation.
+func newStepsJSON(steps []poly.Step) []StepJSON {
+	r := make([]StepJSON, len(steps))
+	for i, v := range steps {
+		r[i] = StepJSON{Kind: string(v.Kind), Text: v.Text(), LaTeX: v.LaTeX()}
+	}
+
+	return r
+}
+
+// Write a complex root exactly, in a ± bi form. The real part is always rational, but the imaginary part is written as a
+// simplified radical unless it is rational. Roots found with Cardano's or Ferrari's method are written as they were
+// found instead.
+func exactComplexRoot(r poly.Root) expr.Node {
+	if r.Radical != nil {
+		return *r.Radical
+	}
+
+	imag := expr.Rat(new(big.Rat).Abs(r.Imag))
+	if !r.Exact {
+		imag = poly.Surd{Rational: new(big.Rat), Coefficient: new(big.Rat).Abs(r.Surd.Coefficient), Radicand: r.Surd.Radicand}.Expr()
+	}
+	im := expr.Product(imag, expr.I)
+	if r.Imag.Sign() < 0 {
+		im = expr.Neg(im)
+	}
+	if r.Value.Sign() == 0 {
+		return im
+	}
+	return expr.Sum(expr.Rat(r.Value), im)
+}
+
+// Convert a surd into its JSON representation.
+func newSurdJSON(s poly.Surd) *SurdJSON {
+	return &SurdJSON{
+		Rational:    newRationalJSON(s.Rational),
+		Coefficient: newRationalJSON(s.Coefficient),
+		Radicand:    json.Number(s.Radicand.String()),
+	}
+}
+
+// Convert a rational number into its JSON representation.
+func newRationalJSON(v *big.Rat) RationalJSON {
+	return RationalJSON{json.Number(v.Num().String()), json.Number(v.Denom().String())}
+}
+
+// Format a complex number in a ± bi form with parts written to the given number of decimal places
+//  formatComplex(-1/2, √3/2, 5) -> "-0.5 + 0.86603i"
+func formatComplex(re, im *big.Rat, digits int) string {
+	return joinComplex(formatRat(re, digits), re.Sign(), formatRat(new(big.Rat).Abs(im), digits), im.Sign())
+}
+
+// Join the real part and the size of the imaginary part of a complex number, leaving out a real part of 0 and an
+// imaginary coefficient of 1.
+//  joinComplex("2", 1, "3", -1) -> "2 - 3i"
+func joinComplex(re string, reSign int, im string, imSign int) string {
+	if im == "1" {
+		im = ""
+	}
+
+	switch {
+	case reSign == 0 && imSign < 0:
+		return "-" + im + "i"
+	case reSign == 0:
+		return im + "i"
+	case imSign < 0:
+		return re + " - " + im + "i"
+	default:
+		return re + " + " + im + "i"
+	}
+}
+
+// The linear factor (x - root) of a real root, which is written exactly unless the root was only approximated, in which
+// case it is written to the given number of decimal places
+//  rootFactor(-2, 5) -> x + 2
+//  rootFactor((1 + √3) / 2, 5) -> x - (1 + √3) / 2
+func rootFactor(r poly.Root, digits int) expr.Node {
+	x := expr.Var("x")
+	switch {
+	case r.Exact:
+		return expr.Sum(x, expr.Rat(new(big.Rat).Neg(r.Value)))
+	case r.Radical != nil:
+		return expr.Sum(x, expr.Neg(*r.Radical))
+	case r.Surd != nil:
+		return surdFactor(*r.Surd)
+	}
+
+	return expr.Sum(x, decimal(new(big.Rat).Neg(r.Value), digits))
+}
+
+// The linear factor (x - root) of a root of the form a + b√n. A root over a denominator is subtracted or added whole,
+// whichever leaves its numerator starting with a positive term.
+//  surdFactor(1 + √2) -> x - 1 - √2
+//  surdFactor((-1 + √3) / 2) -> x + (1 - √3) / 2
+func surdFactor(s poly.Surd) expr.Node {
+	var (
+		x   = expr.Var("x")
+		neg = poly.Surd{Rational: new(big.Rat).Neg(s.Rational), Coefficient: new(big.Rat).Neg(s.Coefficient), Radicand: s.Radicand}
+	)
+	if (neg.Rational.IsInt() && neg.Coefficient.IsInt()) || neg.Rational.Sign() > 0 || (neg.Rational.Sign() == 0 && neg.Coefficient.Sign() > 0) {
+		return expr.Sum(x, neg.Expr())
+	}
+
+	return expr.Sum(x, expr.Neg(s.Expr()))
+}
+
+// The linear factor (x - root) of a complex root, which is written exactly like exactComplexRoot unless the root was
+// only approximated, in which case it is written to the given number of decimal places
+//  complexFactor(-1 + 2i, 5) -> x + 1 - 2i
+//  complexFactor(-1/2 + (√3 / 2)i, 5) -> x + 1/2 - (√3 / 2)i
+func complexFactor(r poly.Root, digits int) expr.Node {
+	x := expr.Var("x")
+	if r.Radical != nil {
+		return expr.Sum(x, expr.Neg(*r.Radical))
+	}
+
+	re, imag := decimal(new(big.Rat).Neg(r.Value), digits), decimal(new(big.Rat).Abs(r.Imag), digits)
+	if r.Exact {
+		re, imag = expr.Rat(new(big.Rat).Neg(r.Value)), expr.Rat(new(big.Rat).Abs(r.Imag))
+	} else if r.Bound == nil && r.Surd != nil {
+		re = expr.Rat(new(big.Rat).Neg(r.Value))
+		imag = poly.Surd{Rational: new(big.Rat), Coefficient: new(big.Rat).Abs(r.Surd.Coefficient), Radicand: r.Surd.Radicand}.Expr()
+	}
+
+	terms := []expr.Node{x}
+	if r.Value.Sign() != 0 {
+		terms = append(terms, re)
+	}
+	im := expr.Product(imag, expr.I)
+	if r.Imag.Sign() > 0 {
+		im = expr.Neg(im)
+	}
+	return expr.Sum(append(terms, im)...)
+}
+
+// Sort a pair of complex conjugate roots so that the root with the negative imaginary part comes first.
+func sortComplex(roots []poly.Root) []poly.Root {
+	r := append([]poly.Root(nil), roots...)
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Imag.Cmp(r[j].Imag) < 0
+	})
+	return r
+}
+
+// The number v as a decimal with the given number of decimal places, which is negated if it is negative
+//  decimal(-45, 5) -> -45
+func decimal(v *big.Rat, digits int) expr.Node {
+	r := formatRat(v, digits)
+	if strings.HasPrefix(r, "-") {
+		return expr.Neg(expr.Number(r[1:]))
+	}
+
+	return expr.Number(r)
+}
+
+// Format a number found numerically, which has at most poly.MaxDigits decimal places, and take 0's off the end
+//  formatDecimal(1/8) -> "0.125"
+func formatDecimal(v *big.Rat) string {
+	r := strings.TrimRight(strings.TrimRight(v.FloatString(poly.MaxDigits), "0"), ".")
+	if r == "-0" {
+		return "0"
+	}
+
+	return r
+}
+
+// Format v as a decimal with the given number of decimal places and take 0's off the end. A number too small to show in
+// that many decimal places is written with that many significant figures instead, so that it isn't mistaken for 0.
+//  formatRat(-1/123456789, 5) -> "-8.1e-09"
+func formatRat(v *big.Rat, digits int) string {
+	r := strings.TrimRight(strings.TrimRight(v.FloatString(digits), "0"), ".")
+	if (r == "0" || r == "-0") && v.Sign() != 0 {
+		return new(big.Float).SetRat(v).Text('g', digits)
+	} else if r == "-0" {
+		return "0"
+	}
+
+	return r
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The version of the OpenAPI specification that the document follows.
+const openAPIVersion = "3.0.3"
+
+// Struct defining an OpenAPI document, with only the parts of the specification that describe this API.
+type openAPIJSON struct {
+	OpenAPI    string                       `json:"openapi"`
+	Info       openAPIInfoJSON              `json:"info"`
+	Servers    []openAPIServerJSON          `json:"servers"`
+	Paths      map[string]map[string]opJSON `json:"paths"` // The operations of each path, by lowercase method
+	Components struct {
+		Schemas map[string]*schemaJSON `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIInfoJSON struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServerJSON struct {
+	URL string `json:"url"`
+}
+
+// Struct defining an OpenAPI operation, which is a method of a path.
+type opJSON struct {
+	OperationID string                  `json:"operationId"`
+	Description string                  `json:"description"`
+	Parameters  []paramJSON             `json:"parameters,omitempty"`
+	RequestBody *requestBodyJSON        `json:"requestBody,omitempty"`
+	Responses   map[string]responseJSON `json:"responses"`
+}
+
+type paramJSON struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required,omitempty"`
+	Style       string      `json:"style,omitempty"`
+	Explode     *bool       `json:"explode,omitempty"`
+	Schema      *schemaJSON `json:"schema"`
+}
+
+type requestBodyJSON struct {
+	Description string                   `json:"description,omitempty"`
+	Required    bool                     `json:"required"`
+	Content     map[string]mediaTypeJSON `json:"content"`
+}
+
+type responseJSON struct {
+	Description string                   `json:"description"`
+	Content     map[string]mediaTypeJSON `json:"content,omitempty"`
+}
+
+type mediaTypeJSON struct {
+	Schema *schemaJSON `json:"schema"`
+}
+
+// Struct defining an OpenAPI schema object. A schema with nothing set allows any value.
+type schemaJSON struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Items                *schemaJSON            `json:"items,omitempty"`
+	Properties           map[string]*schemaJSON `json:"properties,omitempty"`
+	AdditionalProperties *schemaJSON            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Example              interface{}            `json:"example,omitempty"`
+}
+
+var (
+	numberType  = reflect.TypeOf(json.Number(""))
+	rawJSONType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// Create the OpenAPI document for the endpoints, whose paths are relative to the server at 'base'. The request and
+// response types of the endpoints are described in the components of the document, along with ProblemJSON, which is
+// the response to any request that fails.
+func newOpenAPIJSON(base string, endpoints []Endpoint) *openAPIJSON {
+	doc := &openAPIJSON{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfoJSON{Title: "Quick Factor API", Version: "1"},
+		Servers: []openAPIServerJSON{{URL: base}},
+		Paths:   map[string]map[string]opJSON{},
+	}
+	doc.Components.Schemas = map[string]*schemaJSON{}
+
+	// Every code that a problem can have is listed, so that clients can switch on them
+	problem := doc.schema(reflect.TypeOf(ProblemJSON{}))
+	codes := doc.Components.Schemas["ProblemJSON"].Properties["code"]
+	for k := range problems {
+		codes.Enum = append(codes.Enum, string(k))
+	}
+	sort.Strings(codes.Enum)
+
+	for _, v := range endpoints {
+		op := opJSON{
+			OperationID: operationID(v.Name),
+			Description: v.Description,
+			Responses: map[string]responseJSON{
+				"default": {
+					Description: "The request failed",
+					Content:     map[string]mediaTypeJSON{"application/problem+json": {Schema: problem}},
+				},
+			},
+		}
+
+		// The response is written in the same media type as the request body, which is JSON if there isn't one
+		mediaTypes := v.MediaTypes
+		if len(mediaTypes) == 0 {
+			mediaTypes = []string{"application/json"}
+		}
+
+		if v.Request != nil {
+			op.RequestBody = doc.requestBody(v, mediaTypes)
+		}
+		for _, p := range v.Params {
+			if p.In == "query" {
+				op.Parameters = append(op.Parameters, newParamJSON(p))
+			}
+		}
+
+		ok := responseJSON{Description: "The request succeeded"}
+		if v.Response != nil {
+			ok.Content = map[string]mediaTypeJSON{}
+			s := doc.schema(reflect.TypeOf(v.Response))
+			for _, t := range mediaTypes {
+				ok.Content[t] = mediaTypeJSON{Schema: s}
+			}
+		}
+		op.Responses["200"] = ok
+
+		doc.Paths["/"+v.Name] = map[string]opJSON{strings.ToLower(v.Method): op}
+	}
+
+	return doc
+}
+
+// Describe the request body of an endpoint. The parameters of the endpoint that are given in the body describe the
+// properties of the request with the same names, and any others describe the body as a whole.
+func (doc *openAPIJSON) requestBody(e Endpoint, mediaTypes []string) *requestBodyJSON {
+	t := reflect.TypeOf(e.Request)
+	s := doc.schema(t)
+
+	var properties map[string]*schemaJSON
+	if t.Kind() == reflect.Struct {
+		properties = doc.Components.Schemas[t.Name()].Properties
+	}
+
+	var description []string
+	for _, p := range e.Params {
+		if p.In != "body" {
+			continue
+		}
+		if v, ok := properties[p.Name]; ok {
+			v.Description, v.Enum, v.Default, v.Example = p.Description, p.Enum, p.Default, p.Example
+		} else {
+			description = append(description, p.Description)
+		}
+	}
+
+	r := &requestBodyJSON{Description: strings.Join(description, " "), Required: true, Content: map[string]mediaTypeJSON{}}
+	for _, v := range mediaTypes {
+		r.Content[v] = mediaTypeJSON{Schema: s}
+	}
+	return r
+}
+
+// Describe a type as a schema. Named structs are described once in the components of the document and referred to from
+// everywhere else, and fields are named and made optional by their JSON tags.
+func (doc *openAPIJSON) schema(t reflect.Type) *schemaJSON {
+	switch t {
+	case numberType:
+		return &schemaJSON{Type: "number"}
+	case rawJSONType:
+		return &schemaJSON{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return doc.schema(t.Elem())
+	case reflect.Bool:
+		return &schemaJSON{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaJSON{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schemaJSON{Type: "number"}
+	case reflect.String:
+		return &schemaJSON{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &schemaJSON{Type: "array", Items: doc.schema(t.Elem())}
+	case reflect.Map:
+		return &schemaJSON{Type: "object", AdditionalProperties: doc.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return doc.structSchema(t)
+		}
+
+		// The schema is added before its fields are described, so that a type that refers to itself doesn't recurse
+		if _, ok := doc.Components.Schemas[t.Name()]; !ok {
+			doc.Components.Schemas[t.Name()] = &schemaJSON{}
+			*doc.Components.Schemas[t.Name()] = *doc.structSchema(t)
+		}
+		return &schemaJSON{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	return &schemaJSON{}
+}
+
+// Describe the exported fields of a struct as the properties of an object. Fields that are always written are required.
+func (doc *openAPIJSON) structSchema(t reflect.Type) *schemaJSON {
+	s := &schemaJSON{Type: "object", Properties: map[string]*schemaJSON{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // Unexported
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		name := tag[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+
+		s.Properties[name] = doc.schema(f.Type)
+		if !hasOption(tag[1:], "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// Describe a query parameter. A parameter of type "object" stands for a set of parameters with names of their own, like
+// 'x^0' to 'x^n', which OpenAPI describes as an object whose properties are each written as a separate parameter.
+func newParamJSON(p Param) paramJSON {
+	s := &schemaJSON{Type: p.Type, Enum: p.Enum, Default: p.Default, Example: p.Example}
+	r := paramJSON{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required, Schema: s}
+
+	if p.Type == "object" {
+		explode := true
+		r.Style, r.Explode = "form", &explode
+		s.AdditionalProperties = &schemaJSON{Type: "string"}
+	}
+	return r
+}
+
+// Make an operation ID out of the name of an endpoint, like "v1Batch" for "v1/batch".
+func operationID(name string) string {
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+// Whether the options of a JSON tag include an option.
+func hasOption(options []string, option string) bool {
+	for _, v := range options {
+		if v == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/noahfriedman-ca/quick-factor/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+var _ = Describe("the OpenAPI document", func() {
+	var doc map[string]interface{}
+	BeforeEach(func() {
+		w := httptest.NewRecorder()
+		api.Router().ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/projects/quick-factor/api/openapi.json", nil))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		doc = nil
+		Expect(json.Unmarshal(w.Body.Bytes(), &doc)).To(Succeed())
+	})
+
+	// Follow a path of keys through the document
+	get := func(keys ...string) interface{} {
+		var v interface{} = doc
+		for _, k := range keys {
+			Expect(v).To(HaveKey(k), strings.Join(keys, "/"))
+			v = v.(map[string]interface{})[k]
+		}
+		return v
+	}
+
+	It("should describe the API", func() {
+		Expect(get("openapi")).To(HavePrefix("3."))
+		Expect(get("servers")).To(ConsistOf(map[string]interface{}{"url": "/projects/quick-factor/api"}))
+		Expect(get("paths")).To(HaveKey("/factor"))
+		Expect(get("paths")).To(HaveKey("/roots"))
+		Expect(get("paths")).To(HaveKey("/v1/factor"))
+		Expect(get("paths")).To(HaveKey("/v1/batch"))
+	})
+	It("should describe the query parameters of GET endpoints", func() {
+		var names []string
+		for _, v := range get("paths", "/factor", "get", "parameters").([]interface{}) {
+			p := v.(map[string]interface{})
+			Expect(p["in"]).To(Equal("query"))
+			names = append(names, p["name"].(string))
+
+			if p["name"] == "mode" {
+				Expect(p["schema"]).To(Equal(map[string]interface{}{
+					"type":    "string",
+					"enum":    []interface{}{"exact", "radical", "numeric"},
+					"default": "exact",
+				}))
+			}
+			if p["name"] == "x^n" {
+				Expect(p["style"]).To(Equal("form"))
+				Expect(p["explode"]).To(BeTrue())
+			}
+		}
+		Expect(names).To(ContainElements("expr", "degree", "x^n", "complex", "mode", "precision", "steps", "format"))
+	})
+	It("should describe responses with the response types", func() {
+		Expect(get("paths", "/factor", "get", "responses", "200", "content", "application/json", "schema")).To(Equal(map[string]interface{}{"$ref": "#/components/schemas/FactorJSON"}))
+		Expect(get("paths", "/factor", "get", "responses", "default", "content", "application/problem+json", "schema")).To(Equal(map[string]interface{}{"$ref": "#/components/schemas/ProblemJSON"}))
+
+		factor := get("components", "schemas", "FactorJSON")
+		Expect(factor).To(HaveKeyWithValue("required", []interface{}{"result"}))
+		Expect(get("components", "schemas", "FactorJSON", "properties", "factored")).To(Equal(map[string]interface{}{"$ref": "#/components/schemas/FactoredJSON"}))
+		Expect(get("components", "schemas", "FactoredJSON", "properties", "intercepts")).To(Equal(map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}))
+		Expect(get("components", "schemas", "RootJSON", "properties", "decimal")).To(Equal(map[string]interface{}{"type": "number"}))
+	})
+	It("should describe request bodies with the request types and their fields", func() {
+		Expect(get("paths", "/v1/factor", "post", "requestBody", "content", "application/json", "schema")).To(Equal(map[string]interface{}{"$ref": "#/components/schemas/FactorRequestV1"}))
+		Expect(get("components", "schemas", "FactorRequestV1", "properties", "domain")).To(HaveKeyWithValue("enum", []interface{}{"real", "complex"}))
+
+		batch := get("paths", "/v1/batch", "post", "requestBody", "content").(map[string]interface{})
+		Expect(batch).To(HaveKey("application/json"))
+		Expect(batch).To(HaveKey("application/x-ndjson"))
+		Expect(get("paths", "/v1/batch", "post", "responses", "200", "content", "application/x-ndjson", "schema", "items")).To(Equal(map[string]interface{}{"$ref": "#/components/schemas/BatchItemJSON"}))
+	})
+	It("should list every problem code", func() {
+		Expect(get("components", "schemas", "ProblemJSON", "properties", "code", "enum")).To(ContainElements("missing_degree", "invalid_coefficient", "zero_leading_coefficient"))
+	})
+	It("should only refer to schemas that it has", func() {
+		var check func(v interface{})
+		check = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if ref, ok := v["$ref"].(string); ok {
+					Expect(ref).To(HavePrefix("#/components/schemas/"))
+					get("components", "schemas", strings.TrimPrefix(ref, "#/components/schemas/"))
+				}
+				for _, v := range v {
+					check(v)
+				}
+			case []interface{}:
+				for _, v := range v {
+					check(v)
+				}
+			}
+		}
+		check(doc)
+	})
+})
@@ -0,0 +1,136 @@
+package poly
+
+import (
+	"context"
+	"math/big"
+)
+
+// Add two polynomials.
+func (p Polynomial) Add(q Polynomial) Polynomial {
+	if len(q) > len(p) {
+		p, q = q, p
+	}
+
+	r := make(Polynomial, len(p))
+	for i := range p {
+		r[i] = new(big.Rat).Set(p[i])
+		if i < len(q) {
+			r[i].Add(r[i], q[i])
+		}
+	}
+
+	return r.trim()
+}
+
+// Subtract q from the polynomial.
+func (p Polynomial) Sub(q Polynomial) Polynomial {
+	return p.Add(q.Scale(big.NewRat(-1, 1)))
+}
+
+// Multiply two polynomials.
+func (p Polynomial) Mul(q Polynomial) Polynomial {
+	p, q = p.trim(), q.trim()
+	if len(p) == 0 || len(q) == 0 {
+		return Polynomial{}
+	}
+
+	r := make(Polynomial, len(p)+len(q)-1)
+	for i := range r {
+		r[i] = new(big.Rat)
+	}
+	for i, a := range p {
+		for j, b := range q {
+			r[i+j].Add(r[i+j], new(big.Rat).Mul(a, b))
+		}
+	}
+
+	return r.trim()
+}
+
+// Multiply every coefficient of the polynomial by 'c'.
+func (p Polynomial) Scale(c *big.Rat) Polynomial {
+	r := make(Polynomial, len(p))
+	for i, v := range p {
+		r[i] = new(big.Rat).Mul(v, c)
+	}
+
+	return r.trim()
+}
+
+// Raise the polynomial to the power of 'n' by repeated squaring.
+func (p Polynomial) Pow(n uint) Polynomial {
+	r := Ints(1)
+	for base := p; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			r = r.Mul(base)
+		}
+		if n > 1 {
+			base = base.Mul(base)
+		}
+	}
+
+	return r
+}
+
+// Divide the polynomial by q, returning the quotient and the remainder. q must not be the zero polynomial.
+func (p Polynomial) DivMod(q Polynomial) (Polynomial, Polynomial) {
+	q = q.trim()
+	r := p.Scale(big.NewRat(1, 1))
+	if len(r) < len(q) {
+		return Polynomial{}, r
+	}
+
+	quotient := make(Polynomial, len(r)-len(q)+1)
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c := new(big.Rat).Quo(r[i+len(q)-1], q[len(q)-1])
+		quotient[i] = c
+		for j, v := range q {
+			r[i+j].Sub(r[i+j], new(big.Rat).Mul(c, v))
+		}
+	}
+
+	return quotient.trim(), r[:len(q)-1].trim()
+}
+
+// The derivative of the polynomial.
+func (p Polynomial) Derivative() Polynomial {
+	if len(p) < 2 {
+		return Polynomial{}
+	}
+
+	r := make(Polynomial, len(p)-1)
+	for i := range r {
+		r[i] = new(big.Rat).Mul(p[i+1], big.NewRat(int64(i+1), 1))
+	}
+
+	return r.trim()
+}
+
+// The polynomial divided by its leading coefficient, so that the leading coefficient is 1.
+func (p Polynomial) Monic() Polynomial {
+	if p.Degree() < 0 {
+		return Polynomial{}
+	}
+
+	return p.Scale(new(big.Rat).Inv(p.Leading()))
+}
+
+// The monic greatest common divisor of two polynomials, calculated with the Euclidean algorithm.
+func GCD(p, q Polynomial) Polynomial {
+	return gcd(context.Background(), p, q)
+}
+
+// Calculate the greatest common divisor of two polynomials like GCD. Returns nil if the context is done.
+func gcd(ctx context.Context, p, q Polynomial) Polynomial {
+	p, q = p.trim(), q.trim()
+	for len(q) > 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		_, r := p.DivMod(q)
+		p, q = q, r.Monic()
+	}
+
+	return p.Monic()
+}
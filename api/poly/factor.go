@@ -0,0 +1,542 @@
+package poly
+
+import (
+	"context"
+	"errors"
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"log"
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Describes how completely a polynomial could be factored.
+type Result string
+
+const (
+	Full      Result = "full"      // Every factor is linear with a rational root
+	Quadratic Result = "quadratic" // Every root was found, but the quadratic formula was needed for some of them
+	Radical   Result = "radical"   // Every root was found, but Cardano's or Ferrari's method was needed for some of them
+	Numeric   Result = "numeric"   // Every root was found, but some of them could only be approximated numerically
+	Partial   Result = "partial"   // Some factors were found, but at least one factor could not be factored further
+	Not       Result = "not"       // No factors could be found
+)
+
+var ErrConstant = errors.New("poly: polynomial must have a degree of at least 1")
+
+// A single factor of a factored polynomial. It is named Component to leave the name Factor for the function.
+type Component struct {
+	Polynomial   Polynomial // The factor itself
+	Multiplicity int        // How many times the factor divides the polynomial
+	Irreducible  bool       // Whether the factor is known to be irreducible over the rationals
+	Roots        []Root     // Real roots of the factor, if they could be found
+	Complex      []Root     // Non-real roots of the factor, which come in conjugate pairs
+}
+
+// A root of a polynomial.
+type Root struct {
+	Value        *big.Rat   // The root itself, or its real part if the root is complex
+	Imag         *big.Rat   // The imaginary part of a complex root, or nil for a real root
+	Exact        bool       // Whether Value and Imag are exact, rather than approximations
+	Surd         *Surd      // If Exact is false, the approximated real root or imaginary part written exactly
+	Radical      *expr.Node // The root written exactly with radicals, if it was found with Cardano's or Ferrari's method
+	Bound        *big.Rat   // For a root found numerically, the largest distance there can be to the true root
+	Multiplicity int        // How many times the root is repeated in the factored polynomial
+}
+
+// The result of factoring a polynomial. The polynomial is equal to Constant multiplied by every factor raised to the
+// power of its multiplicity, and every factor is a primitive integer polynomial with a positive leading coefficient.
+type Factorization struct {
+	Result   Result
+	Constant *big.Rat
+	Factors  []Component
+	Complex  bool     // Whether the polynomial was factored over the complex numbers, see FactorComplex
+	Identity Identity // The special product that the polynomial was recognised as, if any
+	Steps    []Step   // The working that led to the factorization, in order
+}
+
+// Check if the root has an imaginary part.
+func (r Root) IsComplex() bool {
+	return r.Imag != nil && r.Imag.Sign() != 0
+}
+
+// Every real root of every factor, sorted in ascending order. Repeated roots are only listed once.
+func (f Factorization) Roots() []Root {
+	var r []Root
+	for _, v := range f.Factors {
+		r = append(r, v.Roots...)
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Value.Cmp(r[j].Value) < 0
+	})
+	return r
+}
+
+// Every complex root of every factor, sorted by real part and then by imaginary part. Repeated roots are only listed
+// once.
+func (f Factorization) ComplexRoots() []Root {
+	var r []Root
+	for _, v := range f.Factors {
+		r = append(r, v.Complex...)
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if c := r[i].Value.Cmp(r[j].Value); c != 0 {
+			return c < 0
+		}
+		return r[i].Imag.Cmp(r[j].Imag) < 0
+	})
+	return r
+}
+
+// Factor a polynomial as completely as possible over the complex numbers. This is the same as Factor, except that
+// quadratic factors with a negative discriminant are counted as factored into their two complex linear factors, so
+// they no longer leave the polynomial partially factored, or unfactored if the polynomial is such a quadratic.
+func FactorComplex(p Polynomial) (Factorization, error) {
+	return FactorComplexContext(context.Background(), p)
+}
+
+// Factor a polynomial over the complex numbers like FactorComplex, giving up when the context is done.
+func FactorComplexContext(ctx context.Context, p Polynomial) (Factorization, error) {
+	f, e := FactorContext(ctx, p)
+	if e != nil {
+		return f, e
+	}
+	f.Complex = true
+
+	if f.Result == Partial || f.Result == Not {
+		solved := true
+		for _, v := range f.Factors {
+			if len(v.Roots) == 0 && len(v.Complex) == 0 {
+				solved = false
+			}
+		}
+		if solved {
+			f.Result = Quadratic
+		}
+	}
+
+	return f, nil
+}
+
+// Factor a polynomial as completely as possible over the complex numbers, like FactorComplex, and then find the roots of
+// any cubic or quartic factors that are left with Cardano's and Ferrari's methods. The roots are written exactly with
+// radicals, and their values are approximated.
+func FactorRadical(p Polynomial) (Factorization, error) {
+	return FactorRadicalContext(context.Background(), p)
+}
+
+// Factor a polynomial and solve its cubic and quartic factors like FactorRadical, giving up when the context is done.
+func FactorRadicalContext(ctx context.Context, p Polynomial) (Factorization, error) {
+	f, e := FactorComplexContext(ctx, p)
+	if e != nil {
+		return f, e
+	}
+
+	var solved bool
+	for i, v := range f.Factors {
+		if len(v.Roots) > 0 || len(v.Complex) > 0 {
+			continue
+		}
+
+		var (
+			roots []radical
+			kind  StepKind
+		)
+		switch v.Polynomial.Degree() {
+		case 3:
+			roots, kind = solveCubic(v.Polynomial), CubicFormulaStep
+		case 4:
+			roots, kind = solveQuartic(ctx, v.Polynomial), QuarticFormulaStep
+		default:
+			continue
+		}
+
+		if f.Factors[i].Roots, f.Factors[i].Complex = approximateRadicals(v.Polynomial, roots, v.Multiplicity); len(f.Factors[i].Roots)+len(f.Factors[i].Complex) > 0 {
+			f.Steps = append(f.Steps, solveStep(kind, v.Polynomial, 0))
+			solved = true
+		}
+	}
+	if e := ctx.Err(); e != nil {
+		return Factorization{}, e
+	}
+
+	if solved {
+		f.Result = Radical
+		for _, v := range f.Factors {
+			if len(v.Roots) == 0 && len(v.Complex) == 0 {
+				f.Result = Partial
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// Factor a polynomial as completely as possible over the complex numbers, like FactorComplex, and then approximate the
+// roots of any factors that are left to the given number of decimal digits using FindRoots.
+func FactorNumeric(p Polynomial, digits int) (Factorization, error) {
+	return FactorNumericContext(context.Background(), p, digits)
+}
+
+// Factor a polynomial and approximate the roots that are left like FactorNumeric, giving up when the context is done.
+func FactorNumericContext(ctx context.Context, p Polynomial, digits int) (Factorization, error) {
+	f, e := FactorComplexContext(ctx, p)
+	if e != nil {
+		return f, e
+	}
+
+	var solved bool
+	for i, v := range f.Factors {
+		if len(v.Roots) > 0 || len(v.Complex) > 0 {
+			continue
+		}
+
+		roots, e := FindRootsContext(ctx, v.Polynomial, digits)
+		if e != nil {
+			return Factorization{}, e
+		}
+		f.Steps = append(f.Steps, solveStep(NumericStep, v.Polynomial, digits))
+		for _, r := range roots {
+			r.Multiplicity = v.Multiplicity
+			if r.IsComplex() {
+				f.Factors[i].Complex = append(f.Factors[i].Complex, r)
+			} else {
+				f.Factors[i].Roots = append(f.Factors[i].Roots, r)
+			}
+		}
+		solved = true
+	}
+
+	if solved {
+		f.Result = Numeric
+	}
+
+	return f, nil
+}
+
+// Factor a polynomial as completely as possible.
+func Factor(p Polynomial) (Factorization, error) {
+	return FactorContext(context.Background(), p)
+}
+
+// Factor a polynomial as completely as possible like Factor, giving up when the context is done. The error is then the
+// context's error, so a factorization that takes too long returns context.DeadlineExceeded.
+func FactorContext(ctx context.Context, p Polynomial) (Factorization, error) {
+	p = p.trim()
+	if len(p) < 2 {
+		return Factorization{}, ErrConstant
+	}
+
+	// Factor out the largest power of x, which is the same as finding the multiplicity of the root 0
+	var k int
+	for p[k].Sign() == 0 {
+		k++
+	}
+
+	// Only the primitive part is factored, which doesn't change any of the factors, but keeps the working simple
+	f := &Factorization{Result: Full}
+	if len(p)-k > 1 {
+		var (
+			rest    = p[k:].PrimitivePart()
+			content = p[k:].Content()
+		)
+		f = factorInForm(ctx, rest)
+		if f != nil && (k > 0 || content.Cmp(big.NewRat(1, 1)) != 0) {
+			f.Steps = append([]Step{commonFactorStep(p, content, k, rest)}, f.Steps...)
+		}
+	}
+
+	if e := ctx.Err(); e != nil {
+		return Factorization{}, e
+	} else if f == nil {
+		return Factorization{}, errors.New("poly: failed to factor " + p.String())
+	}
+
+	if k > 0 {
+		// Having found x^k, any factor that is left without roots means the polynomial was only partially factored
+		for _, v := range f.Factors {
+			if len(v.Roots) == 0 {
+				f.Result = Partial
+			}
+		}
+		f.Factors = append([]Component{{Polynomial: Ints(0, 1), Multiplicity: k, Roots: []Root{{Value: new(big.Rat), Exact: true}}}}, f.Factors...)
+	}
+
+	// Move every factor's content into the constant factor, so that only primitive factors are left. Factoring over the
+	// integers is complete, so every factor is also irreducible.
+	f.Constant = new(big.Rat).Set(p.Leading())
+	for i, v := range f.Factors {
+		f.Factors[i].Polynomial = v.Polynomial.PrimitivePart()
+		f.Factors[i].Irreducible = true
+		for j := 0; j < v.Multiplicity; j++ {
+			f.Constant.Quo(f.Constant, f.Factors[i].Polynomial.Leading())
+		}
+	}
+
+	// Repeated factors are combined into one, and their roots are given the same multiplicity
+	var merged []Component
+factors:
+	for _, v := range f.Factors {
+		for i := range merged {
+			if merged[i].Polynomial.Equal(v.Polynomial) {
+				merged[i].Multiplicity += v.Multiplicity
+				continue factors
+			}
+		}
+		merged = append(merged, v)
+	}
+	for _, v := range merged {
+		for i := range v.Roots {
+			v.Roots[i].Multiplicity = v.Multiplicity
+		}
+		for i := range v.Complex {
+			v.Complex[i].Multiplicity = v.Multiplicity
+		}
+	}
+	f.Factors = merged
+
+	return *f, nil
+}
+
+// Implements specific factoring rules that can only be applied to a 3-term polynomial.
+func factorTrinomial(p Polynomial) *Factorization {
+	// Ensure that there are the correct number of coefficients
+	if len(p) != 3 {
+		log.Println("factorTrinomial called with not exactly 3 coefficients")
+		return nil
+	}
+
+	// Calculate the components of the quadratic formula, which are also needed for grouping
+	var (
+		negativeB    = new(big.Rat).Neg(p[1])
+		twoA         = new(big.Rat).Mul(big.NewRat(2, 1), p[2])
+		discriminant = new(big.Rat).Mul(p[1], p[1])
+	)
+	discriminant.Sub(discriminant, new(big.Rat).Mul(big.NewRat(4, 1), new(big.Rat).Mul(p[0], p[2])))
+
+	// If the discriminant is negative, there are no real roots, so do not factor further. The roots are the complex
+	// conjugates (-b ± i√(4ac - b²)) / 2a instead.
+	if discriminant.Sign() < 0 {
+		var (
+			negated     = new(big.Rat).Neg(discriminant)
+			imag, exact = sqrtRat(negated)
+			realPart    = new(big.Rat).Quo(negativeB, twoA)
+			roots       = make([]Root, 2)
+		)
+		if !exact {
+			imag = approximateSqrt(negated)
+		}
+		for i, sign := range []int64{1, -1} {
+			roots[i] = Root{Value: new(big.Rat).Set(realPart), Imag: new(big.Rat).Quo(imag, twoA), Exact: exact}
+			roots[i].Imag.Mul(roots[i].Imag, big.NewRat(sign, 1))
+			if !exact {
+				s := NewSurd(new(big.Rat), new(big.Rat).Quo(big.NewRat(sign, 1), twoA), negated)
+				roots[i].Surd = &s
+			}
+		}
+		// Over the real numbers the quadratic doesn't factor at all, but its complex roots are kept for FactorComplex
+		return &Factorization{
+			Result:  Not,
+			Factors: []Component{{Polynomial: p, Multiplicity: 1, Complex: roots}},
+			Steps:   []Step{quadraticFormulaStep(p, discriminant, roots)},
+		}
+	}
+
+	root, exact := sqrtRat(discriminant)
+
+	// If all coefficients are integers, try to factor by grouping. A pair of integers adding to b and multiplying to ac
+	// exists exactly when the discriminant is a perfect square, and the pair is then (b ± √(b² - 4ac)) / 2.
+	if exact && p.IsInt() {
+		var pair [2]*big.Rat
+		for i, v := range []*big.Rat{root, new(big.Rat).Neg(root)} {
+			pair[i] = new(big.Rat).Add(p[1], v)
+			pair[i].Quo(pair[i], big.NewRat(2, 1))
+		}
+
+		// Grouping gives a(x + m/a)(x + n/a), so the roots are -m/a and -n/a
+		f := &Factorization{Result: Full}
+		for _, v := range pair {
+			r := new(big.Rat).Quo(new(big.Rat).Neg(v), p[2])
+			f.Factors = append(f.Factors, Component{Polynomial: linear(r), Multiplicity: 1, Roots: []Root{{Value: r, Exact: true}}})
+		}
+		f.Steps = []Step{groupingStep(p, pair[0], pair[1], f.Factors)}
+
+		return f
+	}
+
+	// Otherwise, use the quadratic formula. Irrational square roots are approximated only here.
+	if !exact {
+		root = approximateSqrt(discriminant)
+	}
+	roots := make([]Root, 2)
+	for i, v := range []*big.Rat{root, new(big.Rat).Neg(root)} {
+		r := new(big.Rat).Add(negativeB, v)
+		roots[i] = Root{Value: r.Quo(r, twoA), Exact: exact}
+		if !exact {
+			// The first root adds the square root and the second subtracts it
+			coefficient := new(big.Rat).Inv(twoA)
+			if i == 1 {
+				coefficient.Neg(coefficient)
+			}
+			s := NewSurd(new(big.Rat).Quo(negativeB, twoA), coefficient, discriminant)
+			roots[i].Surd = &s
+		}
+	}
+
+	steps := []Step{quadraticFormulaStep(p, discriminant, roots)}
+	if exact {
+		f := &Factorization{Result: Full, Steps: steps}
+		for _, v := range roots {
+			f.Factors = append(f.Factors, Component{Polynomial: linear(v.Value), Multiplicity: 1, Roots: []Root{v}})
+		}
+
+		return f
+	}
+	return &Factorization{Result: Quadratic, Factors: []Component{{Polynomial: p, Multiplicity: 1, Roots: roots}}, Steps: steps}
+}
+
+// Implements general factorization rules that can be applied to any polynomial. Returns nil if the context is done.
+func factorPolynomial(ctx context.Context, p Polynomial) *Factorization {
+	// Validate degree value
+	if len(p) < 3 {
+		log.Println("degree was smaller than 2, this shouldn't have happened")
+		return nil
+	} else if len(p) == 3 {
+		log.Println("factorPolynomial was called when factorTrinomial should have been")
+	}
+
+	intercept, nums, dens := findRationalRoot(ctx, p)
+	if ctx.Err() != nil {
+		return nil
+	} else if intercept == nil {
+		f := factorIrrational(ctx, p)
+		if f == nil {
+			return nil
+		}
+		f.Steps = append([]Step{rationalRootStep(p, nil, nums, dens)}, f.Steps...)
+		return f
+	}
+
+	// Divide the polynomial by the discovered intercept
+	quotient, remainder := p.divideByRoot(intercept)
+	if remainder.Sign() != 0 {
+		log.Println("an intercept marked as valid was not")
+		return nil
+	}
+
+	// Recursion
+	var d *Factorization
+	if len(quotient) > 3 {
+		d = factorPolynomial(ctx, quotient)
+	} else {
+		d = factorTrinomial(quotient)
+	}
+	if d == nil {
+		return nil
+	}
+
+	// If any factor is left without roots, the polynomial was only partially factored
+	for _, v := range d.Factors {
+		if len(v.Roots) == 0 {
+			d.Result = Partial
+		}
+	}
+	d.Factors = append(d.Factors, Component{Polynomial: linear(intercept), Multiplicity: 1, Roots: []Root{{Value: intercept, Exact: true}}})
+	d.Steps = append([]Step{rationalRootStep(p, intercept, nums, dens), syntheticDivisionStep(p, intercept, quotient)}, d.Steps...)
+
+	return d
+}
+
+// Factor a polynomial that has no rational roots into irreducible factors over the integers. Only quadratic factors
+// can have their roots found. Returns nil if the context is done.
+func factorIrrational(ctx context.Context, p Polynomial) *Factorization {
+	// A polynomial of degree 3 or less that has no rational roots can't have any factors
+	var factors []intFactor
+	if p.Degree() > 3 {
+		factors = factorIntegers(ctx, p)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if len(factors) == 0 || (len(factors) == 1 && factors[0].multiplicity == 1) {
+		return &Factorization{Result: Not, Factors: []Component{{Polynomial: p, Multiplicity: 1}}, Steps: []Step{irreducibleStep(p)}}
+	}
+
+	f := &Factorization{Result: Quadratic}
+	var steps []Step
+	for _, v := range factors {
+		c := Component{Polynomial: v.f.toRat(), Multiplicity: v.multiplicity}
+		if c.Polynomial.Degree() == 2 {
+			t := factorTrinomial(c.Polynomial)
+			c.Roots, c.Complex = t.Factors[0].Roots, t.Factors[0].Complex
+			steps = append(steps, t.Steps...)
+		}
+
+		// Factors without real roots leave the polynomial only partially factored
+		if len(c.Roots) == 0 {
+			f.Result = Partial
+		}
+
+		f.Factors = append(f.Factors, c)
+	}
+	f.Steps = append([]Step{integerFactoringStep(p, f.Factors)}, steps...)
+
+	return f
+}
+
+// Find any rational root of the polynomial using the rational root theorem, or nil if there are none or the context is
+// done first, along with the numerators and denominators of the candidates. The candidates are shared between no more
+// workers than there are threads to run them on, and no more are handed out once a root is found.
+func findRationalRoot(ctx context.Context, p Polynomial) (root *big.Rat, nums, dens []*big.Int) {
+	// The rational root theorem needs integer coefficients, and scaling the polynomial doesn't change its roots
+	integers := p.scaleToIntegers()
+	nums = findFactorsOf(ctx, new(big.Int).Abs(integers[0]))
+	dens = findFactorsOf(ctx, new(big.Int).Abs(integers[len(integers)-1]))
+
+	var (
+		found   sync.Once
+		stop    = make(chan bool)
+		jobs    = make(chan *big.Rat)
+		wg      sync.WaitGroup
+		workers = runtime.GOMAXPROCS(0)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := range jobs {
+				for _, v := range []*big.Rat{x, new(big.Rat).Neg(x)} {
+					if p.Eval(v).Sign() == 0 {
+						found.Do(func() {
+							root = v
+							close(stop)
+						})
+					}
+				}
+			}
+		}()
+	}
+
+search:
+	for _, num := range nums {
+		for _, den := range dens {
+			select {
+			case jobs <- new(big.Rat).SetFrac(num, den):
+			case <-stop:
+				break search
+			case <-ctx.Done():
+				break search
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, nil, nil
+	}
+	return root, nums, dens
+}
@@ -0,0 +1,281 @@
+package poly_test
+
+import (
+	"context"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+var _ = Describe("the Factor function", func() {
+	// Convert roots to strings so that they can be compared easily
+	rootStrings := func(f poly.Factorization) []string {
+		var r []string
+		for _, v := range f.Roots() {
+			for i := 0; i < v.Multiplicity; i++ {
+				r = append(r, v.Value.RatString())
+			}
+		}
+		return r
+	}
+
+	DescribeTable("when an error should be returned",
+		func(p poly.Polynomial, expected error) {
+			_, e := poly.Factor(p)
+			Expect(e).To(MatchError(expected))
+		},
+		Entry("for a constant", poly.Ints(5), poly.ErrConstant),
+		Entry("for the zero polynomial", poly.Ints(0, 0), poly.ErrConstant),
+	)
+
+	DescribeTable("the results when factoring certain polynomials",
+		func(p poly.Polynomial, result poly.Result, roots []string) {
+			f, e := poly.Factor(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(f.Result).To(Equal(result))
+			Expect(rootStrings(f)).To(Equal(roots))
+		},
+		Entry("a linear polynomial", poly.Ints(-7, 3), poly.Full, []string{"7/3"}),
+		Entry("a trinomial that can be grouped", poly.Ints(10, 7, 1), poly.Full, []string{"-5", "-2"}),
+		Entry("a trinomial with fractional coefficients", poly.New(big.NewRat(1, 6), big.NewRat(-5, 6), big.NewRat(1, 1)), poly.Full, []string{"1/3", "1/2"}),
+		Entry("a cubic", poly.Ints(6, -5, -2, 1), poly.Full, []string{"-2", "1", "3"}),
+		Entry("a polynomial without rational roots", poly.Ints(4, 0, 7, 2), poly.Not, nil),
+		Entry("a polynomial that can only be partially factored", poly.Ints(-20, 14, -37, 2, 1), poly.Partial, []string{"5"}),
+		Entry("a polynomial with a constant term of 0", poly.Ints(0, -4, 0, 1), poly.Full, []string{"-2", "0", "2"}),
+		Entry("a polynomial divisible by a power of x", poly.Ints(0, 0, 0, 2, 1), poly.Full, []string{"-2", "0", "0", "0"}),
+		Entry("a monomial", poly.Ints(0, 0, 3), poly.Full, []string{"0", "0"}),
+		Entry("a power of x times an irreducible polynomial", poly.Ints(0, 0, 1, 0, 1), poly.Partial, []string{"0", "0"}),
+	)
+
+	It("should combine repeated factors", func() {
+		f, e := poly.Factor(poly.Ints(-2, 1).Pow(3).Mul(poly.Ints(1, 1)).Mul(poly.Ints(1, 0, 1).Pow(2)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Factors).To(HaveLen(3))
+
+		multiplicities := make(map[string]int)
+		for _, v := range f.Factors {
+			multiplicities[v.Polynomial.String()] = v.Multiplicity
+			for _, r := range v.Roots {
+				Expect(r.Multiplicity).To(Equal(v.Multiplicity))
+			}
+		}
+		Expect(multiplicities).To(Equal(map[string]int{"x - 2": 3, "x + 1": 1, "x^2 + 1": 2}))
+
+		roots := f.Roots()
+		Expect(roots).To(HaveLen(2))
+		Expect(roots[1].Value.RatString()).To(Equal("2"))
+		Expect(roots[1].Multiplicity).To(Equal(3))
+	})
+
+	It("should multiply back to the original polynomial", func() {
+		p := poly.New(big.NewRat(-3, 2), big.NewRat(4, 1), big.NewRat(-5, 2), big.NewRat(-3, 1), big.NewRat(3, 1))
+		f, e := poly.Factor(p)
+		Expect(e).NotTo(HaveOccurred())
+
+		product := poly.New(f.Constant)
+		for _, v := range f.Factors {
+			Expect(v.Polynomial.IsInt()).To(BeTrue())
+			Expect(v.Polynomial.Content().Cmp(big.NewRat(1, 1))).To(BeZero())
+			product = product.Mul(v.Polynomial.Pow(uint(v.Multiplicity)))
+		}
+		Expect(product.Equal(p)).To(BeTrue())
+	})
+
+	It("should approximate irrational roots found with the quadratic formula", func() {
+		f, e := poly.Factor(poly.Ints(-2, 10, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Quadratic))
+
+		roots := f.Roots()
+		Expect(roots).To(HaveLen(2))
+		for i, v := range []string{"-10.19615", "0.19615"} {
+			Expect(roots[i].Exact).To(BeFalse())
+			Expect(roots[i].Value.FloatString(5)).To(Equal(v))
+		}
+	})
+
+	It("should keep the unfactored part of a partial factorization", func() {
+		f, e := poly.Factor(poly.Ints(-20, 14, -37, 2, 1))
+		Expect(e).NotTo(HaveOccurred())
+
+		var unfactored []poly.Polynomial
+		for _, v := range f.Factors {
+			if len(v.Roots) == 0 {
+				unfactored = append(unfactored, v.Polynomial)
+			}
+		}
+		Expect(unfactored).To(HaveLen(1))
+		Expect(unfactored[0].String()).To(Equal("x^3 + 7x^2 - 2x + 4"))
+	})
+
+	It("should only return irreducible factors", func() {
+		f, e := poly.Factor(poly.Ints(-20, 14, -37, 2, 1).Mul(poly.Ints(1, 0, 1)))
+		Expect(e).NotTo(HaveOccurred())
+
+		for _, v := range f.Factors {
+			Expect(v.Irreducible).To(BeTrue())
+		}
+	})
+
+	It("should find the complex roots of a quadratic with a negative discriminant", func() {
+		f, e := poly.Factor(poly.Ints(5, 2, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Not))
+		Expect(f.Roots()).To(BeEmpty())
+
+		roots := f.ComplexRoots()
+		Expect(roots).To(HaveLen(2))
+		for i, imag := range []int64{-2, 2} {
+			Expect(roots[i].IsComplex()).To(BeTrue())
+			Expect(roots[i].Exact).To(BeTrue())
+			Expect(roots[i].Value).To(Equal(big.NewRat(-1, 1)))
+			Expect(roots[i].Imag).To(Equal(big.NewRat(imag, 1)))
+		}
+	})
+
+	It("should only count complex linear factors when factoring over the complex numbers", func() {
+		p := poly.Ints(0, 1, 0, 1)
+
+		f, e := poly.Factor(p)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Partial))
+		Expect(f.Complex).To(BeFalse())
+
+		f, e = poly.FactorComplex(p)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Quadratic))
+		Expect(f.Complex).To(BeTrue())
+
+		// A quadratic with complex roots on its own isn't factored at all over the real numbers
+		f, e = poly.Factor(poly.Ints(5, 2, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Not))
+
+		f, e = poly.FactorComplex(poly.Ints(5, 2, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Quadratic))
+
+		// Cubic factors without rational roots can't be split any further
+		f, e = poly.FactorComplex(poly.Ints(0, -1, -1, 0, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Partial))
+	})
+
+	DescribeTable("factoring polynomials in x^k by substitution",
+		func(p poly.Polynomial, result poly.Result, real, complex int, degrees []int) {
+			f, e := poly.FactorComplex(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(f.Result).To(Equal(result))
+			Expect(f.Roots()).To(HaveLen(real))
+			Expect(f.ComplexRoots()).To(HaveLen(complex))
+
+			var actual []int
+			product := poly.Ints(1).Scale(f.Constant)
+			for _, v := range f.Factors {
+				actual = append(actual, v.Polynomial.Degree())
+				product = product.Mul(v.Polynomial.Pow(uint(v.Multiplicity)))
+			}
+			Expect(actual).To(ConsistOf(degrees))
+			Expect(product.Equal(p)).To(BeTrue())
+		},
+		Entry("a biquadratic with rational roots", poly.Ints(4, 0, -5, 0, 1), poly.Full, 4, 0, []int{1, 1, 1, 1}),
+		Entry("a biquadratic with irrational roots", poly.Ints(6, 0, -5, 0, 1), poly.Quadratic, 4, 0, []int{2, 2}),
+		Entry("a sextic that is quadratic in x^3", poly.Ints(-8, 0, 0, 7, 0, 0, 1), poly.Quadratic, 2, 4, []int{1, 1, 2, 2}),
+		Entry("a difference of squares and cubes", poly.Ints(-64, 0, 0, 0, 0, 0, 1), poly.Quadratic, 2, 4, []int{1, 1, 2, 2}),
+		Entry("a sum of cubes", poly.Ints(27, 0, 0, 8), poly.Quadratic, 1, 2, []int{1, 2}),
+		Entry("a repeated factor in x^2", poly.Ints(-1, 0, 1).Pow(2).Mul(poly.Ints(3, 0, 1)), poly.Quadratic, 2, 2, []int{1, 1, 2}),
+		Entry("a binomial that doesn't factor completely", poly.Ints(-1, 0, 0, 0, 0, 0, 0, 0, 1), poly.Partial, 2, 2, []int{1, 1, 2, 4}),
+		Entry("an irreducible polynomial in x^2", poly.Ints(1, 0, 0, 0, 1), poly.Not, 0, 0, []int{4}),
+	)
+
+	Describe("with a context", func() {
+		It("should give up with the context's error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, e := poly.FactorContext(ctx, poly.Ints(6, -5, -2, 1))
+			Expect(e).To(Equal(context.Canceled))
+			_, e = poly.FactorNumericContext(ctx, poly.Ints(-1, -1, 0, 0, 0, 1), 10)
+			Expect(e).To(Equal(context.Canceled))
+
+			ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+			defer cancel()
+			_, e = poly.FactorRadicalContext(ctx, poly.Ints(-20, 14, -37, 2, 1))
+			Expect(e).To(Equal(context.DeadlineExceeded))
+			_, e = poly.FindRootsContext(ctx, poly.Ints(-2, 0, 1), 10)
+			Expect(e).To(Equal(context.DeadlineExceeded))
+		})
+		DescribeTable("giving up soon after the deadline passes",
+			func(expr string) {
+				p, e := poly.Parse(expr)
+				Expect(e).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				start := time.Now()
+				_, e = poly.FactorContext(ctx, p)
+				Expect(e).To(Equal(context.DeadlineExceeded))
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			},
+			Entry("while factoring modulo a prime", "x^1000 - 2"),
+			Entry("while factoring a constant with large prime factors", "x^3 + x - 30000000000018200000000002759"),
+			Entry("while trying a constant with many factors", "x^3 + x - 6^200"),
+		)
+		It("should factor as usual while the context is not done", func() {
+			f, e := poly.FactorContext(context.Background(), poly.Ints(6, -5, -2, 1))
+			Expect(e).NotTo(HaveOccurred())
+			Expect(rootStrings(f)).To(Equal([]string{"-2", "1", "3"}))
+		})
+		It("should give up soon after the deadline for a large polynomial", func() {
+			rng := rand.New(rand.NewSource(1))
+			coefficients := make([]int64, 401)
+			for i := range coefficients {
+				coefficients[i] = rng.Int63n(2001) - 1000
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, e := poly.FactorContext(ctx, poly.Ints(coefficients...))
+			Expect(e).To(Equal(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+		It("should only try candidates on a fixed number of goroutines", func() {
+			p, e := poly.Parse("x^3 + x - 6^200")
+			Expect(e).NotTo(HaveOccurred())
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			// The constant has tens of thousands of factors, and each of them is a candidate
+			before, most := runtime.NumGoroutine(), 0
+			done := make(chan bool)
+			go func() {
+				_, _ = poly.FactorContext(ctx, p)
+				close(done)
+			}()
+			for running := true; running; {
+				select {
+				case <-done:
+					running = false
+				case <-time.After(time.Millisecond):
+					if n := runtime.NumGoroutine(); n > most {
+						most = n
+					}
+				}
+			}
+			Expect(most - before).To(BeNumerically("<=", runtime.GOMAXPROCS(0)+2))
+		})
+		It("should not leave any goroutines behind", func() {
+			before := runtime.NumGoroutine()
+
+			// Candidates are tried by a pool of goroutines, which stop once a root is found
+			_, e := poly.Factor(poly.Ints(-1, 1).Mul(poly.Ints(-2, 1)).Mul(poly.Ints(-3, 1)).Mul(poly.Ints(-6, 1)).Mul(poly.Ints(1, 1)).Mul(poly.Ints(2, 1)))
+			Expect(e).NotTo(HaveOccurred())
+			Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", before))
+		})
+	})
+})
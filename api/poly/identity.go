@@ -0,0 +1,74 @@
+package poly
+
+import (
+	"context"
+	"math/big"
+)
+
+// A special product that a polynomial was recognised as, which gives its factors directly.
+type Identity string
+
+const (
+	DifferenceOfSquares Identity = "difference of squares" // a² - b² = (a - b)(a + b)
+	SumOfCubes          Identity = "sum of cubes"          // a³ + b³ = (a + b)(a² - ab + b²)
+	DifferenceOfCubes   Identity = "difference of cubes"   // a³ - b³ = (a - b)(a² + ab + b²)
+	SumOfPowers         Identity = "sum of powers"         // aⁿ + bⁿ = (a + b)(aⁿ⁻¹ - aⁿ⁻²b + ... + bⁿ⁻¹) for odd n
+	DifferenceOfPowers  Identity = "difference of powers"  // aⁿ - bⁿ = (a - b)(aⁿ⁻¹ + aⁿ⁻²b + ... + bⁿ⁻¹)
+	PerfectSquare       Identity = "perfect square"        // a² ± 2ab + b² = (a ± b)²
+	BinomialExpansion   Identity = "binomial expansion"    // aⁿ + naⁿ⁻¹b + ... + bⁿ = (a + b)ⁿ
+)
+
+// Factor a polynomial with a nonzero constant term if it is a binomial, using the identities that factorBinomial knows,
+// or if it is a binomial expansion. Returns nil if it is neither.
+//  4x^2 - 9 -> difference of squares
+//  x^3 + 6x^2 + 12x + 8 -> binomial expansion
+func factorSpecialProduct(ctx context.Context, p Polynomial) *Factorization {
+	n := p.Degree()
+	if n < 2 {
+		return nil
+	}
+
+	// A binomial ax^n + c is x^n - r, scaled by a, where r = -c/a
+	terms := 0
+	for _, v := range p {
+		if v.Sign() != 0 {
+			terms++
+		}
+	}
+	if terms == 2 {
+		f := factorBinomial(ctx, n, new(big.Rat).Quo(new(big.Rat).Neg(p[0]), p[n]))
+		if f != nil && p[n].Cmp(big.NewRat(1, 1)) != 0 {
+			f.Steps = append([]Step{leadingCoefficientStep(p)}, f.Steps...)
+		}
+		return f
+	}
+
+	// (x + a)^n has x^(n-1) coefficient na, so a is the only possible value. Its x^(n-k) coefficient is C(n, k)a^k, so the
+	// rest of the coefficients are checked one at a time, and most polynomials fail long before (x + a)^n is expanded.
+	a := new(big.Rat).Quo(p[n-1], new(big.Rat).Mul(big.NewRat(int64(n), 1), p[n]))
+	term := new(big.Rat).Set(p[n])
+	for k := 1; k <= n; k++ {
+		term.Mul(term, a).Mul(term, big.NewRat(int64(n-k+1), int64(k)))
+		if term.Cmp(p[n-k]) != 0 {
+			return nil
+		}
+	}
+
+	identity := BinomialExpansion
+	if n == 2 {
+		identity = PerfectSquare
+	}
+	root := new(big.Rat).Neg(a)
+
+	// When the leading coefficient is t^n, the working shows (tx + ta)^n rather than t^n(x + a)^n
+	factor := linear(root)
+	if t, ok := rootRat(p[n], n); ok && t.Sign() > 0 {
+		factor = factor.Scale(t)
+	}
+	return &Factorization{
+		Result:   Full,
+		Factors:  []Component{{Polynomial: linear(root), Multiplicity: n, Roots: []Root{{Value: root, Exact: true}}}},
+		Identity: identity,
+		Steps:    []Step{identityStep(identity, p, Polynomial{new(big.Rat), factor[1]}, factor[0], n, []Component{{Polynomial: factor, Multiplicity: n}})},
+	}
+}
@@ -0,0 +1,48 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("recognising special products", func() {
+	DescribeTable("the identity used to factor a polynomial",
+		func(p poly.Polynomial, identity poly.Identity, result poly.Result) {
+			f, e := poly.Factor(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(f.Identity).To(Equal(identity))
+			Expect(f.Result).To(Equal(result))
+
+			product := poly.Ints(1).Scale(f.Constant)
+			for _, v := range f.Factors {
+				product = product.Mul(v.Polynomial.Pow(uint(v.Multiplicity)))
+			}
+			Expect(product.Equal(p)).To(BeTrue())
+		},
+		Entry("a difference of squares", poly.Ints(-9, 0, 4), poly.DifferenceOfSquares, poly.Full),
+		Entry("a difference of squares with fractions", poly.New(big.NewRat(-1, 4), new(big.Rat), big.NewRat(1, 1)), poly.DifferenceOfSquares, poly.Full),
+		Entry("a difference of squares of higher powers", poly.Ints(-16, 0, 0, 0, 1), poly.DifferenceOfSquares, poly.Partial),
+		Entry("a sum of cubes", poly.Ints(27, 0, 0, 8), poly.SumOfCubes, poly.Partial),
+		Entry("a difference of cubes", poly.Ints(-1, 0, 0, 1), poly.DifferenceOfCubes, poly.Partial),
+		Entry("a difference of cubes of a number above 2^53", poly.New(new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(-12345678901234567), big.NewInt(3), nil)), new(big.Rat), new(big.Rat), big.NewRat(1, 1)), poly.DifferenceOfCubes, poly.Partial),
+		Entry("a sum of powers", poly.Ints(32, 0, 0, 0, 0, 1), poly.SumOfPowers, poly.Partial),
+		Entry("a difference of powers", poly.Ints(-32, 0, 0, 0, 0, 1), poly.DifferenceOfPowers, poly.Partial),
+		Entry("a perfect square trinomial", poly.Ints(9, 12, 4), poly.PerfectSquare, poly.Full),
+		Entry("a binomial expansion", poly.Ints(8, 12, 6, 1), poly.BinomialExpansion, poly.Full),
+		Entry("a binomial expansion with a fraction", poly.New(big.NewRat(1, 8), big.NewRat(3, 4), big.NewRat(3, 2), big.NewRat(1, 1)), poly.BinomialExpansion, poly.Full),
+		Entry("a power of x times a difference of squares", poly.Ints(0, -4, 0, 1), poly.DifferenceOfSquares, poly.Full),
+		Entry("a binomial without an identity", poly.Ints(-2, 0, 1), poly.Identity(""), poly.Quadratic),
+		Entry("a trinomial without an identity", poly.Ints(6, -5, 1), poly.Identity(""), poly.Full),
+	)
+
+	It("should factor a binomial expansion into a repeated linear factor", func() {
+		f, e := poly.Factor(poly.Ints(-1, 3, -3, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Factors).To(HaveLen(1))
+		Expect(f.Factors[0].Multiplicity).To(Equal(3))
+		Expect(f.Roots()[0].Value.RatString()).To(Equal("1"))
+	})
+})
@@ -0,0 +1,282 @@
+package poly
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+)
+
+// A polynomial with coefficients in the integers modulo a small prime, stored from the constant term upwards. Every
+// coefficient is kept in the range [0, p).
+type gfPoly []int64
+
+// Reduce an integer polynomial modulo p.
+func gfFromInt(f intPoly, p int64) gfPoly {
+	bp := big.NewInt(p)
+
+	r := make(gfPoly, len(f))
+	for i, v := range f {
+		r[i] = new(big.Int).Mod(v, bp).Int64()
+	}
+
+	return r.trim()
+}
+
+// Convert the polynomial back into an integer polynomial with coefficients in [0, p).
+func (f gfPoly) toInt() intPoly {
+	r := make(intPoly, len(f))
+	for i, v := range f {
+		r[i] = big.NewInt(v)
+	}
+
+	return r
+}
+
+func (f gfPoly) trim() gfPoly {
+	for len(f) > 0 && f[len(f)-1] == 0 {
+		f = f[:len(f)-1]
+	}
+
+	return f
+}
+
+func (f gfPoly) degree() int {
+	return len(f.trim()) - 1
+}
+
+func gfAdd(f, g gfPoly, p int64) gfPoly {
+	if len(g) > len(f) {
+		f, g = g, f
+	}
+
+	r := make(gfPoly, len(f))
+	copy(r, f)
+	for i, v := range g {
+		r[i] = (r[i] + v) % p
+	}
+
+	return r.trim()
+}
+
+func gfSub(f, g gfPoly, p int64) gfPoly {
+	n := make(gfPoly, len(g))
+	for i, v := range g {
+		n[i] = (p - v) % p
+	}
+
+	return gfAdd(f, n, p)
+}
+
+func gfMul(f, g gfPoly, p int64) gfPoly {
+	if len(f) == 0 || len(g) == 0 {
+		return gfPoly{}
+	}
+
+	r := make(gfPoly, len(f)+len(g)-1)
+	for i, a := range f {
+		if a == 0 {
+			continue
+		}
+		for j, b := range g {
+			r[i+j] = (r[i+j] + a*b) % p
+		}
+	}
+
+	return r.trim()
+}
+
+// Divide f by g, returning the quotient and the remainder. g must not be the zero polynomial.
+func gfDivMod(f, g gfPoly, p int64) (gfPoly, gfPoly) {
+	f, g = f.trim(), g.trim()
+
+	r := make(gfPoly, len(f))
+	copy(r, f)
+	if len(r) < len(g) {
+		return gfPoly{}, r
+	}
+
+	inv := gfInverse(g[len(g)-1], p)
+	quotient := make(gfPoly, len(r)-len(g)+1)
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c := r[i+len(g)-1] * inv % p
+		quotient[i] = c
+		for j, v := range g {
+			r[i+j] = ((r[i+j]-c*v)%p + p) % p
+		}
+	}
+
+	return quotient.trim(), r[:len(g)-1].trim()
+}
+
+func gfMonic(f gfPoly, p int64) gfPoly {
+	f = f.trim()
+	if len(f) == 0 {
+		return f
+	}
+
+	inv := gfInverse(f[len(f)-1], p)
+	r := make(gfPoly, len(f))
+	for i, v := range f {
+		r[i] = v * inv % p
+	}
+
+	return r
+}
+
+// The monic greatest common divisor of f and g.
+func gfGCD(f, g gfPoly, p int64) gfPoly {
+	f, g = f.trim(), g.trim()
+	for len(g) > 0 {
+		_, r := gfDivMod(f, g, p)
+		f, g = g, r
+	}
+
+	return gfMonic(f, p)
+}
+
+// The extended Euclidean algorithm, returning the monic gcd along with s and t such that sf + tg = gcd.
+func gfExtendedGCD(f, g gfPoly, p int64) (gcd, s, t gfPoly) {
+	var (
+		r0, r1 = f.trim(), g.trim()
+		s0, s1 = gfPoly{1}, gfPoly{}
+		t0, t1 = gfPoly{}, gfPoly{1}
+	)
+	for len(r1) > 0 {
+		q, r := gfDivMod(r0, r1, p)
+		r0, r1 = r1, r
+		s0, s1 = s1, gfSub(s0, gfMul(q, s1, p), p)
+		t0, t1 = t1, gfSub(t0, gfMul(q, t1, p), p)
+	}
+
+	// Make the gcd monic, scaling s and t along with it
+	inv := gfPoly{gfInverse(r0[len(r0)-1], p)}
+	return gfMul(r0, inv, p), gfMul(s0, inv, p), gfMul(t0, inv, p)
+}
+
+// Raise f to the power of e modulo m using repeated squaring. Returns nil if the context is done, since e can have
+// thousands of bits.
+func gfPowMod(ctx context.Context, f gfPoly, e *big.Int, m gfPoly, p int64) gfPoly {
+	var (
+		r       = gfPoly{1}
+		_, base = gfDivMod(f, m, p)
+	)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		_, r = gfDivMod(gfMul(r, r, p), m, p)
+		if e.Bit(i) == 1 {
+			_, r = gfDivMod(gfMul(r, base, p), m, p)
+		}
+	}
+
+	return r
+}
+
+func gfDerivative(f gfPoly, p int64) gfPoly {
+	if len(f) < 2 {
+		return gfPoly{}
+	}
+
+	r := make(gfPoly, len(f)-1)
+	for i := range r {
+		r[i] = f[i+1] * int64(i+1) % p
+	}
+
+	return r.trim()
+}
+
+// The multiplicative inverse of 'a' modulo the prime p.
+func gfInverse(a, p int64) int64 {
+	return new(big.Int).ModInverse(big.NewInt(a), big.NewInt(p)).Int64()
+}
+
+// Factor a monic square-free polynomial modulo the odd prime p into monic irreducible factors, using distinct-degree
+// factorization followed by the Cantor-Zassenhaus algorithm. Returns nil if the context is done.
+func gfFactor(ctx context.Context, f gfPoly, p int64) []gfPoly {
+	var (
+		r   []gfPoly
+		rng = rand.New(rand.NewSource(int64(len(f)) * p)) // Seeded deterministically so results are reproducible
+	)
+	for _, v := range gfDistinctDegree(ctx, f, p) {
+		r = append(r, gfEqualDegree(ctx, v.f, v.degree, p, rng)...)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return r
+}
+
+// A product of irreducible factors that all share the same degree.
+type gfDegreeProduct struct {
+	f      gfPoly
+	degree int
+}
+
+// Split a monic square-free polynomial into products of irreducible factors with equal degrees. Returns nil if the
+// context is done.
+func gfDistinctDegree(ctx context.Context, f gfPoly, p int64) []gfDegreeProduct {
+	var (
+		r  []gfDegreeProduct
+		x  = gfPoly{0, 1}
+		h  = x
+		bp = big.NewInt(p)
+	)
+	for i := 1; f.degree() >= 2*i; i++ {
+		// After i steps h is x^(p^i), and x^(p^i) - x is the product of every irreducible of degree dividing i
+		if h = gfPowMod(ctx, h, bp, f, p); h == nil {
+			return nil
+		}
+		if g := gfGCD(gfSub(h, x, p), f, p); g.degree() > 0 {
+			r = append(r, gfDegreeProduct{g, i})
+			f, _ = gfDivMod(f, g, p)
+			_, h = gfDivMod(h, f, p)
+		}
+	}
+	if f.degree() > 0 {
+		r = append(r, gfDegreeProduct{gfMonic(f, p), f.degree()})
+	}
+
+	return r
+}
+
+// Split a monic product of irreducible factors that all have the given degree into those factors. Returns nil if the
+// context is done.
+func gfEqualDegree(ctx context.Context, f gfPoly, degree int, p int64, rng *rand.Rand) []gfPoly {
+	n := f.degree()
+	if n <= degree {
+		return []gfPoly{f}
+	}
+
+	// Half of the nonzero elements of GF(p^degree) are squares, so a^((p^degree - 1) / 2) - 1 shares about half of the
+	// factors of f with a random polynomial a
+	e := new(big.Int).Exp(big.NewInt(p), big.NewInt(int64(degree)), nil)
+	e.Sub(e, big.NewInt(1)).Rsh(e, 1)
+	for ctx.Err() == nil {
+		a := make(gfPoly, n)
+		for i := range a {
+			a[i] = rng.Int63n(p)
+		}
+		if a = a.trim(); a.degree() < 1 {
+			continue
+		}
+
+		g := gfGCD(a, f, p)
+		if g.degree() == 0 {
+			power := gfPowMod(ctx, a, e, f, p)
+			if power == nil {
+				return nil
+			}
+			g = gfGCD(gfSub(power, gfPoly{1}, p), f, p)
+		}
+
+		if d := g.degree(); d > 0 && d < n {
+			h, _ := gfDivMod(f, g, p)
+			return append(gfEqualDegree(ctx, g, degree, p, rng), gfEqualDegree(ctx, gfMonic(h, p), degree, p, rng)...)
+		}
+	}
+
+	return nil
+}
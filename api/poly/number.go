@@ -0,0 +1,191 @@
+package poly
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sort"
+)
+
+// Calculate the positive factors of 'x' in ascending order. 'x' must be positive. Returns nil if the context is done.
+func findFactorsOf(ctx context.Context, x *big.Int) []*big.Int {
+	// Check that x is not 0
+	if x.Sign() <= 0 {
+		log.Println("there are no factors of 0, and this shouldn't have happened")
+		return nil
+	}
+
+	primes := primeFactorsOf(ctx, x)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	// Every factor is a product of some of the prime factors, so build them up one prime at a time. A repeated prime
+	// only multiplies the factors that the previous copy of it made, so that no factor is made twice.
+	var (
+		r    = []*big.Int{big.NewInt(1)} // 1 is a factor of everything
+		last int                          // Where the factors made by the previous prime start
+	)
+	for i, p := range primes {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		start := 0
+		if i > 0 && p.Cmp(primes[i-1]) == 0 {
+			start = last
+		}
+		last = len(r)
+		for _, v := range r[start:last] {
+			r = append(r, new(big.Int).Mul(v, p))
+		}
+	}
+
+	// Sort the array and return
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Cmp(r[j]) < 0
+	})
+	return r
+}
+
+// Calculate the prime factors of 'x' in ascending order, with repeated primes listed as many times as they divide 'x'.
+// 'x' must be positive. Returns nil if the context is done.
+func primeFactorsOf(ctx context.Context, x *big.Int) []*big.Int {
+	var (
+		r         []*big.Int
+		remaining = new(big.Int).Set(x)
+		one       = big.NewInt(1)
+	)
+
+	// Trial division takes care of small primes quickly
+	for p := int64(2); p < 1000 && remaining.Cmp(one) > 0; p++ {
+		for bp := big.NewInt(p); new(big.Int).Rem(remaining, bp).Sign() == 0; {
+			r = append(r, bp)
+			remaining.Quo(remaining, bp)
+		}
+	}
+
+	// Anything left over is split up using Pollard's rho algorithm
+	stack := []*big.Int{remaining}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.Cmp(one) == 0 {
+			continue
+		} else if n.ProbablyPrime(20) {
+			r = append(r, n)
+			continue
+		}
+
+		d := pollardRho(ctx, n)
+		if d == nil {
+			return nil
+		}
+		stack = append(stack, d, new(big.Int).Quo(n, d))
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Cmp(r[j]) < 0
+	})
+	return r
+}
+
+// Find a non-trivial factor of the composite number 'n' using Pollard's rho algorithm. Returns nil if the context is
+// done first.
+func pollardRho(ctx context.Context, n *big.Int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		var (
+			x = big.NewInt(2)
+			y = big.NewInt(2)
+			d = big.NewInt(1)
+			f = func(v *big.Int) *big.Int {
+				v.Mul(v, v).Add(v, big.NewInt(c)).Mod(v, n)
+				return v
+			}
+		)
+
+		for d.Cmp(one) == 0 {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			f(x)
+			f(f(y))
+			d.GCD(nil, nil, new(big.Int).Abs(new(big.Int).Sub(x, y)), n)
+		}
+
+		// If d == n the cycle was found without a factor, so try again with a different constant
+		if d.Cmp(n) != 0 {
+			return d
+		}
+	}
+}
+
+// Calculate the exact square root of a non-negative rational, if it has one.
+func sqrtRat(x *big.Rat) (*big.Rat, bool) {
+	num := new(big.Int).Sqrt(x.Num())
+	den := new(big.Int).Sqrt(x.Denom())
+	if new(big.Int).Mul(num, num).Cmp(x.Num()) != 0 || new(big.Int).Mul(den, den).Cmp(x.Denom()) != 0 {
+		return nil, false
+	}
+
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// Calculate the exact cube root of a rational, if it has one. Negative rationals have negative cube roots.
+func cbrtRat(x *big.Rat) (*big.Rat, bool) {
+	return rootRat(x, 3)
+}
+
+// Calculate the exact nth root of a rational, if it has one. Negative rationals only have a real root when n is odd.
+func rootRat(x *big.Rat, n int) (*big.Rat, bool) {
+	if x.Sign() < 0 && n%2 == 0 {
+		return nil, false
+	}
+
+	num, den := integerRoot(x.Num(), n), integerRoot(x.Denom(), n)
+	if num == nil || den == nil {
+		return nil, false
+	}
+
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// Calculate the exact nth root of an integer, or nil if it doesn't have one. The floor of the root is found with
+// Newton's method in integer arithmetic, starting from a power of 2 that is at least the root, and then checked.
+//  integerRoot(-27, 3) -> -3
+func integerRoot(x *big.Int, n int) *big.Int {
+	if x.Sign() < 0 && n%2 == 0 {
+		return nil
+	} else if x.Sign() == 0 {
+		return new(big.Int)
+	}
+
+	abs := new(big.Int).Abs(x)
+	r := new(big.Int).Lsh(big.NewInt(1), uint((abs.BitLen()+n-1)/n))
+	for bigN, bigN1 := big.NewInt(int64(n)), big.NewInt(int64(n-1)); ; {
+		// next = ((n - 1)r + |x| / r^(n-1)) / n, which decreases until it reaches the floor of the root
+		next := new(big.Int).Quo(abs, new(big.Int).Exp(r, bigN1, nil))
+		next.Add(next, new(big.Int).Mul(bigN1, r))
+		next.Quo(next, bigN)
+		if next.Cmp(r) >= 0 {
+			break
+		}
+		r = next
+	}
+
+	if new(big.Int).Exp(r, big.NewInt(int64(n)), nil).Cmp(abs) != 0 {
+		return nil
+	} else if x.Sign() < 0 {
+		r.Neg(r)
+	}
+	return r
+}
+
+// Approximate the square root of a non-negative rational to well beyond the precision that is ever displayed.
+func approximateSqrt(x *big.Rat) *big.Rat {
+	r, _ := new(big.Float).SetPrec(256).SetRat(x).Sqrt(new(big.Float).SetPrec(256).SetRat(x)).Rat(nil)
+	return r
+}
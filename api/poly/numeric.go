@@ -0,0 +1,291 @@
+package poly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// The largest number of decimal digits that roots can be found to numerically.
+const MaxDigits = 100
+
+var ErrNotConverged = errors.New("poly: numeric root finding did not converge")
+
+// A complex number with arbitrary precision parts.
+type bigComplex struct {
+	re, im *big.Float
+}
+
+func newBigComplex(re, im float64, prec uint) bigComplex {
+	return bigComplex{new(big.Float).SetPrec(prec).SetFloat64(re), new(big.Float).SetPrec(prec).SetFloat64(im)}
+}
+
+func (a bigComplex) prec() uint {
+	return a.re.Prec()
+}
+
+func (a bigComplex) add(b bigComplex) bigComplex {
+	return bigComplex{new(big.Float).SetPrec(a.prec()).Add(a.re, b.re), new(big.Float).SetPrec(a.prec()).Add(a.im, b.im)}
+}
+
+func (a bigComplex) sub(b bigComplex) bigComplex {
+	return bigComplex{new(big.Float).SetPrec(a.prec()).Sub(a.re, b.re), new(big.Float).SetPrec(a.prec()).Sub(a.im, b.im)}
+}
+
+func (a bigComplex) mul(b bigComplex) bigComplex {
+	var (
+		prec = a.prec()
+		re   = new(big.Float).SetPrec(prec).Mul(a.re, b.re)
+		im   = new(big.Float).SetPrec(prec).Mul(a.re, b.im)
+	)
+	re.Sub(re, new(big.Float).SetPrec(prec).Mul(a.im, b.im))
+	im.Add(im, new(big.Float).SetPrec(prec).Mul(a.im, b.re))
+
+	return bigComplex{re, im}
+}
+
+func (a bigComplex) quo(b bigComplex) bigComplex {
+	var (
+		prec = a.prec()
+		norm = b.norm()
+		re   = new(big.Float).SetPrec(prec).Mul(a.re, b.re)
+		im   = new(big.Float).SetPrec(prec).Mul(a.im, b.re)
+	)
+	re.Add(re, new(big.Float).SetPrec(prec).Mul(a.im, b.im)).Quo(re, norm)
+	im.Sub(im, new(big.Float).SetPrec(prec).Mul(a.re, b.im)).Quo(im, norm)
+
+	return bigComplex{re, im}
+}
+
+// The square of the absolute value.
+func (a bigComplex) norm() *big.Float {
+	r := new(big.Float).SetPrec(a.prec()).Mul(a.re, a.re)
+	return r.Add(r, new(big.Float).SetPrec(a.prec()).Mul(a.im, a.im))
+}
+
+func (a bigComplex) abs() *big.Float {
+	return a.norm().Sqrt(a.norm())
+}
+
+func (a bigComplex) isZero() bool {
+	return a.re.Sign() == 0 && a.im.Sign() == 0
+}
+
+// Find every complex root of the polynomial numerically to the given number of decimal digits, using the Aberth-Ehrlich
+// method. Repeated roots are found once with their multiplicity, and the roots are sorted by their real parts and then
+// by their imaginary parts.
+//
+// Every root comes with a bound on its error: the true root lies within that distance of the approximation. Real roots
+// have no imaginary part, and are only reported as real when their error bound allows it.
+func FindRoots(p Polynomial, digits int) ([]Root, error) {
+	return FindRootsContext(context.Background(), p, digits)
+}
+
+// Find every complex root of the polynomial numerically like FindRoots, giving up with the context's error when it is
+// done.
+func FindRootsContext(ctx context.Context, p Polynomial, digits int) ([]Root, error) {
+	if p.Degree() < 1 {
+		return nil, ErrConstant
+	} else if digits < 1 || digits > MaxDigits {
+		return nil, fmt.Errorf("poly: digits must be between 1 and %d", MaxDigits)
+	}
+
+	// Aberth's method converges slowly to repeated roots, so each square-free part is solved separately
+	parts := squareFree(ctx, p)
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	var r []Root
+	for _, v := range parts {
+		roots, e := aberth(ctx, v.f.toRat(), digits)
+		if e != nil {
+			return nil, e
+		}
+		for i := range roots {
+			roots[i].Multiplicity = v.multiplicity
+		}
+		r = append(r, roots...)
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if c := r[i].Value.Cmp(r[j].Value); c != 0 {
+			return c < 0
+		}
+		return r[i].imag().Cmp(r[j].imag()) < 0
+	})
+	return r, nil
+}
+
+// Approximate the kth root of a positive number, to about the precision of a float64. The exponent is divided
+// separately, so the number can be far outside the range of a float64.
+//  approximateRoot(1e400, 2) -> 1e200
+func approximateRoot(x *big.Float, k int) *big.Float {
+	mant := new(big.Float)
+	exp := x.MantExp(mant)
+	m, _ := mant.Float64()
+
+	// x = m·2^exp, so its root is m^(1/k)·2^(r/k)·2^q, where exp = qk + r
+	q, r := exp/k, exp%k
+	root := math.Pow(m, 1/float64(k)) * math.Pow(2, float64(r)/float64(k))
+	return new(big.Float).SetPrec(x.Prec()).SetMantExp(big.NewFloat(root), q)
+}
+
+// Find the roots of a square-free polynomial with the Aberth-Ehrlich method, which improves every approximation at
+// once. Each correction is Newton's correction, adjusted to push the approximations away from each other so that they
+// don't converge to the same root.
+func aberth(ctx context.Context, p Polynomial, digits int) ([]Root, error) {
+	var (
+		n         = p.Degree()
+		prec      = uint(float64(digits)*math.Log2(10)) + 64 // Extra bits make up for rounding errors
+		tolerance = new(big.Float).SetPrec(prec).SetRat(new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits+1)), nil)))
+
+		coefficients = make([]bigComplex, len(p))
+		derivative   = make([]bigComplex, len(p)-1)
+	)
+	for i, v := range p {
+		coefficients[i] = bigComplex{new(big.Float).SetPrec(prec).SetRat(v), new(big.Float).SetPrec(prec)}
+		if i > 0 {
+			d := new(big.Rat).Mul(v, big.NewRat(int64(i), 1))
+			derivative[i-1] = bigComplex{new(big.Float).SetPrec(prec).SetRat(d), new(big.Float).SetPrec(prec)}
+		}
+	}
+	eval := func(c []bigComplex, z bigComplex) bigComplex {
+		r := newBigComplex(0, 0, prec)
+		for i := len(c) - 1; i >= 0; i-- {
+			r = r.mul(z).add(c[i])
+		}
+		return r
+	}
+
+	// The starting points are spread around a circle that contains every root, using Fujiwara's bound on their size,
+	// which is twice the largest |a(n-k) / a(n)|^(1/k). It is worked out with big floats, since the coefficients can be far
+	// outside the range of a float64. The circle is rotated slightly so that no starting point is real, since real
+	// starting points can't become complex.
+	bound := new(big.Float).SetPrec(prec)
+	for k := 1; k <= n; k++ {
+		if c := new(big.Rat).Quo(p[n-k], p[n]); c.Sign() != 0 {
+			if r := approximateRoot(new(big.Float).SetPrec(prec).SetRat(c.Abs(c)), k); r.Cmp(bound) > 0 {
+				bound = r
+			}
+		}
+	}
+	if bound.Sign() == 0 {
+		bound.SetInt64(1)
+	}
+	bound.Mul(bound, big.NewFloat(2))
+	z := make([]bigComplex, n)
+	for i := range z {
+		angle := 2*math.Pi*float64(i)/float64(n) + 0.4
+		z[i] = bigComplex{
+			new(big.Float).SetPrec(prec).Mul(bound, big.NewFloat(math.Cos(angle))),
+			new(big.Float).SetPrec(prec).Mul(bound, big.NewFloat(math.Sin(angle))),
+		}
+	}
+
+	// Each iteration roughly triples the number of correct digits, so this is far more than should ever be needed
+	converged := false
+	for iteration := 0; iteration < 100+10*n && !converged; iteration++ {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		converged = true
+		for i := range z {
+			value := eval(coefficients, z[i])
+			if value.isZero() {
+				continue
+			}
+
+			newton := value.quo(eval(derivative, z[i]))
+			sum := newBigComplex(0, 0, prec)
+			for j := range z {
+				if j != i {
+					sum = sum.add(newBigComplex(1, 0, prec).quo(z[i].sub(z[j])))
+				}
+			}
+			correction := newton.quo(newBigComplex(1, 0, prec).sub(newton.mul(sum)))
+			z[i] = z[i].sub(correction)
+
+			size := z[i].abs()
+			if size.Cmp(big.NewFloat(1)) < 0 {
+				size.SetFloat64(1)
+			}
+			if correction.abs().Cmp(new(big.Float).Mul(tolerance, size)) > 0 {
+				converged = false
+			}
+		}
+	}
+
+	// The disks around each approximation with radius n|p(z)| / |lc·∏(z - other approximations)| contain every root,
+	// and each connected group of k disks contains exactly k roots
+	var (
+		r      = make([]Root, n)
+		lcBig  = coefficients[n]
+		nBig   = new(big.Float).SetPrec(prec).SetInt64(int64(n))
+		limit  = new(big.Float).SetPrec(prec).Mul(tolerance, big.NewFloat(10))
+		bounds = make([]*big.Float, n)
+	)
+	for i := range z {
+		product := lcBig
+		for j := range z {
+			if j != i {
+				product = product.mul(z[i].sub(z[j]))
+			}
+		}
+		if product.isZero() {
+			return nil, ErrNotConverged
+		}
+		bounds[i] = eval(coefficients, z[i]).quo(product).abs()
+		bounds[i].Mul(bounds[i], nBig)
+
+		size := z[i].abs()
+		if size.Cmp(big.NewFloat(1)) < 0 {
+			size.SetFloat64(1)
+		}
+		if bounds[i].Cmp(new(big.Float).Mul(limit, size)) > 0 {
+			return nil, ErrNotConverged
+		}
+	}
+
+	// Rounding to the requested number of digits moves each part by at most half of the last digit, so the error bound
+	// grows by the last digit to cover both parts
+	rounding := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+	for i, v := range z {
+		bound, _ := bounds[i].Rat(nil)
+		r[i] = Root{Value: roundFloat(v.re, digits), Bound: bound.Add(bound, rounding)}
+
+		// The roots of a polynomial with real coefficients come in conjugate pairs, so a root whose disk reaches the
+		// real axis is taken to be real, since its conjugate would otherwise share the same disk
+		if new(big.Float).Abs(v.im).Cmp(bounds[i]) > 0 {
+			r[i].Imag = roundFloat(v.im, digits)
+		}
+	}
+
+	return r, nil
+}
+
+// Round a number to the given number of decimal places, and convert it into a rational.
+func roundFloat(f *big.Float, digits int) *big.Rat {
+	r, _ := f.Rat(nil)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+	r.Mul(r, scale)
+
+	// Round half away from zero
+	num := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	num.Add(num, new(big.Int).Mul(r.Denom(), big.NewInt(int64(r.Sign()))))
+	num.Quo(num, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+
+	return new(big.Rat).Quo(new(big.Rat).SetInt(num), scale)
+}
+
+// The imaginary part of the root, which is 0 for real roots.
+func (r Root) imag() *big.Rat {
+	if r.Imag == nil {
+		return new(big.Rat)
+	}
+
+	return r.Imag
+}
@@ -0,0 +1,76 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("finding roots numerically", func() {
+	DescribeTable("finding every root",
+		func(p poly.Polynomial, real []string, complex int) {
+			roots, e := poly.FindRoots(p, 15)
+			Expect(e).NotTo(HaveOccurred())
+
+			var actual []string
+			n := 0
+			for _, v := range roots {
+				n += v.Multiplicity
+				Expect(v.Bound.Cmp(new(big.Rat).SetFrac64(1, 1e14))).To(BeNumerically("<", 0))
+				if !v.IsComplex() {
+					actual = append(actual, v.Value.FloatString(6))
+				}
+			}
+			Expect(actual).To(Equal(real))
+			Expect(n).To(Equal(p.Degree()))
+			Expect(len(roots) - len(actual)).To(Equal(complex))
+		},
+		Entry("for a quadratic", poly.Ints(-2, 0, 1), []string{"-1.414214", "1.414214"}, 0),
+		Entry("for a quintic with one real root", poly.Ints(-1, -1, 0, 0, 0, 1), []string{"1.167304"}, 4),
+		Entry("for a polynomial without real roots", poly.Ints(1, 0, 0, 0, 0, 0, 1), nil, 6),
+		Entry("for repeated roots", poly.Ints(-1, -1, 0, 0, 0, 1).Pow(2).Mul(poly.Ints(-3, 1)), []string{"1.167304", "3.000000"}, 4),
+		Entry("for roots that are very close together", poly.Ints(9999, -20000, 10000), []string{"0.990000", "1.010000"}, 0),
+	)
+
+	DescribeTable("finding roots when the coefficients are outside the range of a float64",
+		func(coefficients []string, root string) {
+			var p poly.Polynomial
+			for _, v := range coefficients {
+				c, _ := new(big.Rat).SetString(v)
+				p = append(p, c)
+			}
+
+			roots, e := poly.FindRoots(p, 10)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(roots).To(HaveLen(2))
+			Expect(new(big.Float).SetRat(roots[1].Value).Text('g', 6)).To(Equal(root))
+		},
+		Entry("for a huge constant", []string{"-3e400", "0", "1"}, "1.73205e+200"),
+		Entry("for a constant too large for float64 arithmetic", []string{"-3e300", "0", "1"}, "1.73205e+150"),
+		Entry("for a tiny leading coefficient", []string{"-1", "0", "1e-400"}, "1e+200"),
+		Entry("for a tiny constant", []string{"-3e-400", "0", "1"}, "0"),
+	)
+
+	It("should be accurate to the requested number of digits", func() {
+		roots, e := poly.FindRoots(poly.Ints(-2, 0, 1), 50)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(roots[1].Value.FloatString(50)).To(Equal("1.41421356237309504880168872420969807856967187537695"))
+	})
+
+	It("should reject an invalid number of digits", func() {
+		_, e := poly.FindRoots(poly.Ints(-2, 0, 1), 0)
+		Expect(e).To(HaveOccurred())
+		_, e = poly.FindRoots(poly.Ints(-2, 0, 1), poly.MaxDigits+1)
+		Expect(e).To(HaveOccurred())
+	})
+
+	It("should approximate the factors that are left when factoring", func() {
+		f, e := poly.FactorNumeric(poly.Ints(-1, -1, 0, 0, 0, 1).Mul(poly.Ints(-2, 1)), 10)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Numeric))
+		Expect(f.Roots()).To(HaveLen(2))
+		Expect(f.ComplexRoots()).To(HaveLen(4))
+	})
+})
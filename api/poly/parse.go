@@ -0,0 +1,328 @@
+package poly
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// The largest degree a parsed expression may have, which stops inputs like "x^999999999" from exhausting memory.
+const MaxParseDegree = 1000
+
+// The most bits that the numerator or denominator of a coefficient raised to a power may have, which stops inputs like
+// "((9^999)^999)^99" from exhausting memory.
+const maxParseBits = 1 << 16
+
+// An error encountered while parsing an expression.
+type ParseError struct {
+	Column int    // The column where the error was found, starting at 1
+	Msg    string // A description of the error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("poly: column %d: %s", e.Column, e.Msg)
+}
+
+// The kinds of token that an expression is made up of.
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenVariable
+	tokenPlus
+	tokenMinus
+	tokenTimes
+	tokenDivide
+	tokenPower
+	tokenSuperscript // A run of unicode superscript digits, like ²³
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+// Superscript digits and the regular digits they represent.
+var superscripts = map[rune]rune{
+	'⁰': '0', '¹': '1', '²': '2', '³': '3', '⁴': '4', '⁵': '5', '⁶': '6', '⁷': '7', '⁸': '8', '⁹': '9',
+}
+
+// Parse a polynomial in x from a free-form expression, such as "3x^3 - 2x + 1", "x² - 4" or "2(x + 1)**2 - x".
+//
+// Terms may appear in any order and may be repeated, multiplication may be implied, and parentheses are expanded.
+// Division is only allowed by constants.
+func Parse(s string) (Polynomial, error) {
+	tokens, e := tokenize(s)
+	if e != nil {
+		return nil, e
+	}
+
+	p := &parser{tokens: tokens}
+	r, e := p.expression()
+	if e != nil {
+		return nil, e
+	} else if t := p.peek(); t.kind != tokenEnd {
+		return nil, &ParseError{t.column, fmt.Sprintf("unexpected %q", t.text)}
+	}
+
+	return r, nil
+}
+
+// Split an expression into tokens.
+func tokenize(s string) ([]token, error) {
+	var (
+		r     []token
+		runes = []rune(s)
+	)
+	for i := 0; i < len(runes); {
+		c, column := runes[i], i+1
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case isDigit(c) || c == '.':
+			start := i
+			for i < len(runes) && (isDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			r = append(r, token{tokenNumber, string(runes[start:i]), column})
+			continue
+		case superscripts[c] != 0:
+			var digits []rune
+			for ; i < len(runes) && superscripts[runes[i]] != 0; i++ {
+				digits = append(digits, superscripts[runes[i]])
+			}
+			r = append(r, token{tokenSuperscript, string(digits), column})
+			continue
+		case c == 'x' || c == 'X':
+			r = append(r, token{tokenVariable, string(c), column})
+		case c == '+':
+			r = append(r, token{tokenPlus, string(c), column})
+		case c == '-' || c == '−':
+			r = append(r, token{tokenMinus, string(c), column})
+		case c == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			r = append(r, token{tokenPower, "**", column})
+			i++
+		case c == '*' || c == '·' || c == '×':
+			r = append(r, token{tokenTimes, string(c), column})
+		case c == '/' || c == '÷':
+			r = append(r, token{tokenDivide, string(c), column})
+		case c == '^':
+			r = append(r, token{tokenPower, string(c), column})
+		case c == '(':
+			r = append(r, token{tokenOpen, string(c), column})
+		case c == ')':
+			r = append(r, token{tokenClose, string(c), column})
+		case unicode.IsLetter(c):
+			return nil, &ParseError{column, fmt.Sprintf("unknown variable %q, only x is supported", c)}
+		default:
+			return nil, &ParseError{column, fmt.Sprintf("unexpected character %q", c)}
+		}
+
+		i++
+	}
+
+	return append(r, token{tokenEnd, "end of expression", len(runes) + 1}), nil
+}
+
+// A recursive descent parser over a list of tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+
+	return t
+}
+
+// expression := term (('+' | '-') term)*
+func (p *parser) expression() (Polynomial, error) {
+	r, e := p.term()
+	if e != nil {
+		return nil, e
+	}
+
+	for k := p.peek().kind; k == tokenPlus || k == tokenMinus; k = p.peek().kind {
+		p.next()
+
+		t, e := p.term()
+		if e != nil {
+			return nil, e
+		}
+
+		if k == tokenPlus {
+			r = r.Add(t)
+		} else {
+			r = r.Sub(t)
+		}
+	}
+
+	return r, nil
+}
+
+// term := unary (('*' | '/')? unary)*
+//
+// A unary with no operator in front of it is an implied multiplication, which is only allowed before x or a
+// parenthesis so that "2 3" is not mistaken for 6.
+func (p *parser) term() (Polynomial, error) {
+	r, e := p.unary()
+	if e != nil {
+		return nil, e
+	}
+
+	for {
+		op := p.peek()
+		switch op.kind {
+		case tokenTimes, tokenDivide:
+			p.next()
+		case tokenVariable, tokenOpen:
+		default:
+			return r, nil
+		}
+
+		column := p.peek().column
+		u, e := p.unary()
+		if e != nil {
+			return nil, e
+		}
+
+		if op.kind != tokenDivide {
+			r = r.Mul(u)
+		} else if u.Degree() > 0 {
+			return nil, &ParseError{column, "can only divide by a constant"}
+		} else if u.Degree() < 0 {
+			return nil, &ParseError{column, "division by zero"}
+		} else {
+			r = r.Scale(new(big.Rat).Inv(u[0]))
+		}
+
+		if r.Degree() > MaxParseDegree {
+			return nil, &ParseError{column, fmt.Sprintf("degree must not be larger than %d", MaxParseDegree)}
+		}
+	}
+}
+
+// unary := ('+' | '-') unary | power
+func (p *parser) unary() (Polynomial, error) {
+	switch p.peek().kind {
+	case tokenPlus:
+		p.next()
+		return p.unary()
+	case tokenMinus:
+		p.next()
+		r, e := p.unary()
+		if e != nil {
+			return nil, e
+		}
+		return r.Scale(big.NewRat(-1, 1)), nil
+	default:
+		return p.power()
+	}
+}
+
+// power := primary (('^' | '**') unary | superscript)?
+func (p *parser) power() (Polynomial, error) {
+	r, e := p.primary()
+	if e != nil {
+		return nil, e
+	}
+
+	var (
+		exponent Polynomial
+		column   int
+	)
+	switch t := p.peek(); t.kind {
+	case tokenPower:
+		p.next()
+		column = p.peek().column
+		if exponent, e = p.unary(); e != nil {
+			return nil, e
+		}
+	case tokenSuperscript:
+		p.next()
+		column = t.column
+		exponent = New(parseNumber(t.text))
+	default:
+		return r, nil
+	}
+
+	// Exponents have to be non-negative integers to keep the result a polynomial
+	if exponent.Degree() > 0 || (exponent.Degree() == 0 && (!exponent[0].IsInt() || exponent[0].Sign() < 0)) {
+		return nil, &ParseError{column, "exponent must be a non-negative integer"}
+	} else if exponent.Degree() < 0 {
+		return Ints(1), nil
+	}
+
+	n := exponent[0].Num()
+	if !n.IsUint64() || n.Uint64() > MaxParseDegree {
+		return nil, &ParseError{column, fmt.Sprintf("exponent must not be larger than %d", MaxParseDegree)}
+	} else if r.Degree() > 0 && n.Uint64() > uint64(MaxParseDegree/r.Degree()) {
+		return nil, &ParseError{column, fmt.Sprintf("degree must not be larger than %d", MaxParseDegree)}
+	}
+
+	// A power of a coefficient has about n times as many bits as the coefficient
+	bits := 0
+	for _, v := range r {
+		if b := v.Num().BitLen(); b > bits {
+			bits = b
+		}
+		if b := v.Denom().BitLen(); b > bits {
+			bits = b
+		}
+	}
+	if uint64(bits)*n.Uint64() > maxParseBits {
+		return nil, &ParseError{column, "power is too large to calculate"}
+	}
+
+	return r.Pow(uint(n.Uint64())), nil
+}
+
+// primary := number | 'x' | '(' expression ')'
+func (p *parser) primary() (Polynomial, error) {
+	switch t := p.next(); t.kind {
+	case tokenNumber:
+		if strings.Count(t.text, ".") > 1 || t.text == "." {
+			return nil, &ParseError{t.column, fmt.Sprintf("invalid number %q", t.text)}
+		}
+		return New(parseNumber(t.text)), nil
+	case tokenVariable:
+		return Ints(0, 1), nil
+	case tokenOpen:
+		r, e := p.expression()
+		if e != nil {
+			return nil, e
+		}
+		if c := p.next(); c.kind != tokenClose {
+			return nil, &ParseError{c.column, fmt.Sprintf("expected \")\" but found %q", c.text)}
+		}
+		return r, nil
+	default:
+		return nil, &ParseError{t.column, fmt.Sprintf("expected a number, x or \"(\" but found %q", t.text)}
+	}
+}
+
+// Parse a number that has already been checked to be made up of digits and at most one decimal point.
+func parseNumber(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+	return r
+}
+
+// Check if 'c' is an ASCII digit. unicode.IsDigit is not used because it also accepts digits from other scripts.
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,54 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("the Parse function", func() {
+	DescribeTable("when an expression is valid",
+		func(expr string, expected poly.Polynomial) {
+			p, e := poly.Parse(expr)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(p.String()).To(Equal(expected.String()))
+		},
+		Entry("a polynomial in standard form", "3x^3 - 2x + 1", poly.Ints(1, -2, 0, 3)),
+		Entry("unordered and repeated terms", "1 + x + 2x - x^2 + 3", poly.Ints(4, 3, -1)),
+		Entry("'**' for powers", "x**2 - 4", poly.Ints(-4, 0, 1)),
+		Entry("unicode superscripts", "x³ + 2x² + x¹⁰", poly.Ints(0, 0, 2, 1, 0, 0, 0, 0, 0, 0, 1)),
+		Entry("explicit and implicit multiplication", "2*x(x + 1)(x - 1)", poly.Ints(0, -2, 0, 2)),
+		Entry("parentheses raised to a power", "-(x + 1)^2", poly.Ints(-1, -2, -1)),
+		Entry("decimals and division by constants", "x/2 + 0.25", poly.New(big.NewRat(1, 4), big.NewRat(1, 2))),
+		Entry("uppercase X and unicode operators", "2·X² − 1", poly.Ints(-1, 0, 2)),
+		Entry("powers of constants", "2^3x", poly.Ints(0, 8)),
+	)
+
+	DescribeTable("when an expression is invalid",
+		func(expr string, column int) {
+			_, e := poly.Parse(expr)
+			Expect(e).To(HaveOccurred())
+
+			pe, ok := e.(*poly.ParseError)
+			Expect(ok).To(BeTrue())
+			Expect(pe.Column).To(Equal(column))
+		},
+		Entry("an unknown variable", "x + y", 5),
+		Entry("an unexpected character", "x + 2 $", 7),
+		Entry("a missing closing parenthesis", "(x + 1", 7),
+		Entry("an extra closing parenthesis", "x + 1)", 6),
+		Entry("a missing operand", "x + ", 5),
+		Entry("two numbers next to each other", "2 3", 3),
+		Entry("a negative exponent", "x^-1", 3),
+		Entry("a fractional exponent", "x^0.5", 3),
+		Entry("a variable exponent", "2^x", 3),
+		Entry("division by a polynomial", "1/x", 3),
+		Entry("division by zero", "x/0", 3),
+		Entry("an invalid number", "1.2.3x", 1),
+		Entry("a degree that is too large", "x^5000", 3),
+		Entry("a power of a constant that is too large", "((9^999)^999)^99 x", 10),
+		Entry("an empty expression", "", 1),
+	)
+})
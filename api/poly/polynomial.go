@@ -0,0 +1,188 @@
+// Package poly implements exact factoring of polynomials with rational coefficients, independently of the HTTP API.
+package poly
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"math/big"
+)
+
+// A polynomial with rational coefficients. The coefficient of x^i is stored at index i, so the constant term comes first.
+type Polynomial []*big.Rat
+
+// Create a polynomial from its coefficients, starting with the constant term. The coefficients are copied.
+func New(coefficients ...*big.Rat) Polynomial {
+	p := make(Polynomial, len(coefficients))
+	for i, v := range coefficients {
+		p[i] = new(big.Rat).Set(v)
+	}
+
+	return p.trim()
+}
+
+// Create a polynomial from integer coefficients, starting with the constant term.
+//  Ints(-4, 0, 1) -> x^2 - 4
+func Ints(coefficients ...int64) Polynomial {
+	p := make(Polynomial, len(coefficients))
+	for i, v := range coefficients {
+		p[i] = big.NewRat(v, 1)
+	}
+
+	return p.trim()
+}
+
+// The degree of the polynomial. The zero polynomial has degree -1.
+func (p Polynomial) Degree() int {
+	return len(p.trim()) - 1
+}
+
+// The coefficient of the highest power of x, or 0 for the zero polynomial.
+func (p Polynomial) Leading() *big.Rat {
+	if t := p.trim(); len(t) > 0 {
+		return t[len(t)-1]
+	}
+
+	return new(big.Rat)
+}
+
+// Check if every coefficient is an integer.
+func (p Polynomial) IsInt() bool {
+	for _, v := range p {
+		if !v.IsInt() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The content of the polynomial: the rational number that leaves the primitive part when the polynomial is divided by
+// it. Its sign matches the sign of the leading coefficient.
+//  New(-3/2, -6/2, 9/2).Content() -> 3/2
+func (p Polynomial) Content() *big.Rat {
+	if p.Degree() < 0 {
+		return new(big.Rat)
+	}
+
+	// The primitive part has the same leading coefficient as the polynomial once it is multiplied by the content
+	return new(big.Rat).Quo(p.Leading(), p.PrimitivePart().Leading())
+}
+
+// The primitive part of the polynomial: the polynomial with integer coefficients that have no common factor and a
+// positive leading coefficient, which is equal to the polynomial divided by its content.
+//  New(-3/2, -6/2, 9/2).PrimitivePart() -> 3x^2 - 2x - 1
+func (p Polynomial) PrimitivePart() Polynomial {
+	return p.primitive().toRat()
+}
+
+// Evaluate the polynomial at 'x' using Horner's method.
+func (p Polynomial) Eval(x *big.Rat) *big.Rat {
+	r := new(big.Rat)
+	for i := len(p) - 1; i >= 0; i-- {
+		r.Mul(r, x).Add(r, p[i])
+	}
+
+	return r
+}
+
+// Check if two polynomials have the same coefficients.
+func (p Polynomial) Equal(q Polynomial) bool {
+	p, q = p.trim(), q.trim()
+	if len(p) != len(q) {
+		return false
+	}
+
+	for i := range p {
+		if p[i].Cmp(q[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Format the polynomial in standard form with exact coefficients
+//  Ints(4, -2, 7, 1).String() -> "x^3 + 7x^2 - 2x + 4"
+func (p Polynomial) String() string {
+	return p.format("x", false)
+}
+
+// Format the polynomial in standard form as LaTeX
+//  New(1/2, 0, -3).LaTeX() -> "-3x^{2} + \frac{1}{2}"
+func (p Polynomial) LaTeX() string {
+	return p.format("x", true)
+}
+
+// Format the polynomial in standard form in terms of 'variable', either as plain text or as LaTeX.
+func (p Polynomial) format(variable string, latex bool) string {
+	if latex {
+		return expr.LaTeX.Write(p.Expr(variable))
+	}
+
+	return p.Expr(variable).String()
+}
+
+// The expression tree of the polynomial in standard form in terms of 'variable'. Coefficients of 1 are implied unless
+// the term is a constant.
+func (p Polynomial) Expr(variable string) expr.Node {
+	var terms []expr.Node
+	for i := len(p) - 1; i >= 0; i-- {
+		v := p[i]
+		if v.Sign() == 0 {
+			continue
+		}
+
+		term := expr.Rat(new(big.Rat).Abs(v))
+		if i > 0 {
+			term = expr.Product(term, expr.Pow(expr.Var(variable), i))
+		}
+		if v.Sign() < 0 {
+			term = expr.Neg(term)
+		}
+		terms = append(terms, term)
+	}
+
+	return expr.Sum(terms...)
+}
+
+// Remove leading coefficients that are 0.
+func (p Polynomial) trim() Polynomial {
+	for len(p) > 0 && p[len(p)-1].Sign() == 0 {
+		p = p[:len(p)-1]
+	}
+
+	return p
+}
+
+// Divide the polynomial by (x - root) using synthetic division, returning the quotient and the remainder.
+func (p Polynomial) divideByRoot(root *big.Rat) (Polynomial, *big.Rat) {
+	r := make(Polynomial, len(p)-1)
+
+	carry := new(big.Rat)
+	for i := len(p) - 1; i > 0; i-- {
+		carry = new(big.Rat).Add(p[i], new(big.Rat).Mul(carry, root))
+		r[i-1] = carry
+	}
+
+	return r, new(big.Rat).Add(p[0], new(big.Rat).Mul(carry, root))
+}
+
+// Multiply every coefficient by the lowest common denominator so that they are all integers.
+func (p Polynomial) scaleToIntegers() []*big.Int {
+	lcd := big.NewInt(1)
+	for _, v := range p {
+		gcd := new(big.Int).GCD(nil, nil, lcd, v.Denom())
+		lcd.Mul(lcd, new(big.Int).Quo(v.Denom(), gcd))
+	}
+
+	r := make([]*big.Int, len(p))
+	for i, v := range p {
+		r[i] = new(big.Int).Mul(v.Num(), new(big.Int).Quo(lcd, v.Denom()))
+	}
+
+	return r
+}
+
+// Create the monic linear polynomial (x - root).
+func linear(root *big.Rat) Polynomial {
+	return Polynomial{new(big.Rat).Neg(root), big.NewRat(1, 1)}
+}
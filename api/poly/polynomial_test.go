@@ -0,0 +1,42 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("the Polynomial type", func() {
+	It("should ignore leading coefficients that are 0", func() {
+		p := poly.Ints(1, 2, 0, 0)
+		Expect(p.Degree()).To(Equal(1))
+		Expect(p.Leading().Cmp(big.NewRat(2, 1))).To(BeZero())
+		Expect(p.Equal(poly.Ints(1, 2))).To(BeTrue())
+	})
+	It("should evaluate exactly", func() {
+		p := poly.New(big.NewRat(-7, 1), big.NewRat(3, 1))
+		Expect(p.Eval(big.NewRat(7, 3)).Sign()).To(BeZero())
+	})
+	DescribeTable("splitting into content and primitive part",
+		func(p poly.Polynomial, content *big.Rat, primitive poly.Polynomial) {
+			Expect(p.Content().Cmp(content)).To(BeZero())
+			Expect(p.PrimitivePart().String()).To(Equal(primitive.String()))
+			Expect(p.PrimitivePart().Scale(p.Content()).Equal(p)).To(BeTrue())
+		},
+		Entry("with a common integer factor", poly.Ints(36, 30, 6), big.NewRat(6, 1), poly.Ints(6, 5, 1)),
+		Entry("with a negative leading coefficient", poly.Ints(4, 0, -1), big.NewRat(-1, 1), poly.Ints(-4, 0, 1)),
+		Entry("with fractional coefficients", poly.New(big.NewRat(-3, 2), big.NewRat(-3, 1), big.NewRat(9, 2)), big.NewRat(3, 2), poly.Ints(-1, -2, 3)),
+		Entry("when already primitive", poly.Ints(7, -10, 3), big.NewRat(1, 1), poly.Ints(7, -10, 3)),
+	)
+	DescribeTable("formatting in standard form",
+		func(p poly.Polynomial, expected string) {
+			Expect(p.String()).To(Equal(expected))
+		},
+		Entry("with integer coefficients", poly.Ints(4, -2, 7, 1), "x^3 + 7x^2 - 2x + 4"),
+		Entry("with a negative leading coefficient", poly.Ints(-1, 0, -1), "-x^2 - 1"),
+		Entry("with fractional coefficients", poly.New(big.NewRat(1, 2), big.NewRat(-3, 4)), "-(3/4)x + 1/2"),
+		Entry("for the zero polynomial", poly.Ints(), "0"),
+	)
+})
@@ -0,0 +1,310 @@
+package poly
+
+import (
+	"context"
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// A number written exactly using radicals, along with its approximate value. The value always uses the same branch of
+// every root as the expression: principal square roots, real cube roots of real numbers and principal cube roots
+// otherwise.
+type radical struct {
+	expr  expr.Node
+	value complex128
+	rat   *big.Rat // The exact value if it is rational, otherwise nil
+	surd  *Surd    // The exact value if it is a real surd, otherwise nil
+}
+
+func ratRadical(v *big.Rat) radical {
+	f, _ := v.Float64()
+	return radical{expr: expr.Rat(v), value: complex(f, 0), rat: new(big.Rat).Set(v)}
+}
+
+func surdRadical(s Surd) radical {
+	f, _ := s.Approximate().Float64()
+	return radical{expr: s.Expr(), value: complex(f, 0), surd: &s}
+}
+
+func (a radical) isZero() bool {
+	return a.rat != nil && a.rat.Sign() == 0
+}
+
+func addRadical(a, b radical) radical {
+	switch {
+	case a.rat != nil && b.rat != nil:
+		return ratRadical(new(big.Rat).Add(a.rat, b.rat))
+	case a.isZero():
+		return b
+	case b.isZero():
+		return a
+	case a.rat != nil && b.surd != nil:
+		return surdRadical(Surd{new(big.Rat).Add(a.rat, b.surd.Rational), b.surd.Coefficient, b.surd.Radicand})
+	case a.surd != nil && b.rat != nil:
+		return surdRadical(Surd{new(big.Rat).Add(a.surd.Rational, b.rat), a.surd.Coefficient, a.surd.Radicand})
+	}
+
+	return radical{expr: expr.Sum(a.expr, b.expr), value: snap(a.value + b.value)}
+}
+
+func negRadical(a radical) radical {
+	switch {
+	case a.rat != nil:
+		return ratRadical(new(big.Rat).Neg(a.rat))
+	case a.surd != nil:
+		return surdRadical(Surd{new(big.Rat).Neg(a.surd.Rational), new(big.Rat).Neg(a.surd.Coefficient), a.surd.Radicand})
+	}
+
+	return radical{expr: expr.Neg(a.expr), value: -a.value}
+}
+
+func mulRadical(a, b radical) radical {
+	switch {
+	case a.rat != nil && b.rat != nil:
+		return ratRadical(new(big.Rat).Mul(a.rat, b.rat))
+	case a.isZero() || b.isZero():
+		return ratRadical(new(big.Rat))
+	case b.rat != nil:
+		// Rational factors always come first
+		a, b = b, a
+	}
+	if a.rat != nil && b.surd != nil {
+		return surdRadical(Surd{new(big.Rat).Mul(a.rat, b.surd.Rational), new(big.Rat).Mul(a.rat, b.surd.Coefficient), b.surd.Radicand})
+	} else if a.rat != nil && a.rat.Sign() < 0 {
+		return negRadical(mulRadical(ratRadical(new(big.Rat).Neg(a.rat)), b))
+	}
+
+	return radical{expr: expr.Product(a.expr, b.expr), value: snap(a.value * b.value)}
+}
+
+func quoRadical(a, b radical) radical {
+	if a.rat != nil && b.rat != nil {
+		return ratRadical(new(big.Rat).Quo(a.rat, b.rat))
+	} else if a.isZero() {
+		return a
+	} else if a.surd != nil && b.rat != nil {
+		return mulRadical(ratRadical(new(big.Rat).Inv(b.rat)), a)
+	}
+
+	return radical{expr: expr.Frac(a.expr, b.expr), value: snap(a.value / b.value)}
+}
+
+// The principal square root. Square roots of rationals are simplified, and written with i if they are negative.
+func sqrtRadical(a radical) radical {
+	if a.rat == nil {
+		return radical{expr: expr.Sqrt(a.expr), value: snap(cmplx.Sqrt(a.value))}
+	}
+
+	abs := new(big.Rat).Abs(a.rat)
+	if v, ok := sqrtRat(abs); ok {
+		r := ratRadical(v)
+		if a.rat.Sign() < 0 {
+			return mulRadical(r, radical{expr: expr.I, value: 1i})
+		}
+		return r
+	}
+
+	r := surdRadical(NewSurd(new(big.Rat), big.NewRat(1, 1), abs))
+	if a.rat.Sign() < 0 {
+		// i goes between the coefficient and the radical, like 2i√3
+		c := r.surd.Coefficient
+		r.expr = expr.Frac(expr.Product(expr.Int(c.Num()), expr.I, expr.Sqrt(expr.Int(r.surd.Radicand))), expr.Int(c.Denom()))
+		r.value = complex(0, real(r.value))
+		r.surd = nil
+	}
+	return r
+}
+
+// The cube root, which is the real cube root of a real number and the principal cube root otherwise.
+func cbrtRadical(a radical) radical {
+	if a.rat != nil {
+		num, den := new(big.Int).Abs(a.rat.Num()), a.rat.Denom()
+		if n, d := integerCbrt(num), integerCbrt(den); n != nil && d != nil {
+			v := new(big.Rat).SetFrac(n, d)
+			if a.rat.Sign() < 0 {
+				v.Neg(v)
+			}
+			return ratRadical(v)
+		}
+	}
+
+	var value complex128
+	if imag(a.value) == 0 {
+		value = complex(math.Cbrt(real(a.value)), 0)
+	} else {
+		value = cmplx.Pow(a.value, 1./3)
+	}
+
+	return radical{expr: expr.Cbrt(a.expr), value: value}
+}
+
+// The exact cube root of a non-negative integer, or nil if it isn't a perfect cube.
+func integerCbrt(x *big.Int) *big.Int {
+	return integerRoot(x, 3)
+}
+
+// Remove rounding errors from the imaginary part of numbers that are really real.
+func snap(z complex128) complex128 {
+	if math.Abs(imag(z)) <= 1e-12*cmplx.Abs(z) {
+		return complex(real(z), 0)
+	}
+
+	return z
+}
+
+// Find the roots of a cubic with Cardano's method.
+func solveCubic(p Polynomial) []radical {
+	var (
+		a, b, c, d = p[3], p[2], p[1], p[0]
+		three      = big.NewRat(3, 1)
+	)
+
+	// Substituting x = t - b/3a gives the depressed cubic t³ + Pt + Q
+	shift := ratRadical(new(big.Rat).Quo(new(big.Rat).Neg(b), new(big.Rat).Mul(three, a)))
+	pDepressed := new(big.Rat).Sub(new(big.Rat).Mul(three, new(big.Rat).Mul(a, c)), new(big.Rat).Mul(b, b))
+	pDepressed.Quo(pDepressed, new(big.Rat).Mul(three, new(big.Rat).Mul(a, a)))
+	q := new(big.Rat).Mul(big.NewRat(2, 1), new(big.Rat).Mul(b, new(big.Rat).Mul(b, b)))
+	q.Sub(q, new(big.Rat).Mul(big.NewRat(9, 1), new(big.Rat).Mul(a, new(big.Rat).Mul(b, c))))
+	q.Add(q, new(big.Rat).Mul(big.NewRat(27, 1), new(big.Rat).Mul(a, new(big.Rat).Mul(a, d))))
+	q.Quo(q, new(big.Rat).Mul(big.NewRat(27, 1), new(big.Rat).Mul(a, new(big.Rat).Mul(a, a))))
+
+	// t = ∛(-Q/2 + √Δ) + ∛(-Q/2 - √Δ), where Δ = Q²/4 + P³/27
+	discriminant := new(big.Rat).Quo(new(big.Rat).Mul(q, q), big.NewRat(4, 1))
+	discriminant.Add(discriminant, new(big.Rat).Quo(new(big.Rat).Mul(pDepressed, new(big.Rat).Mul(pDepressed, pDepressed)), big.NewRat(27, 1)))
+
+	var (
+		half  = ratRadical(new(big.Rat).Quo(new(big.Rat).Neg(q), big.NewRat(2, 1)))
+		root  = sqrtRadical(ratRadical(discriminant))
+		plus  = cbrtRadical(addRadical(half, root))
+		minus = cbrtRadical(addRadical(half, negRadical(root)))
+	)
+
+	// The other two roots multiply the cube roots by the complex cube roots of unity ω = (-1 + i√3) / 2 and ω²
+	var (
+		i3     = sqrtRadical(ratRadical(big.NewRat(-3, 1)))
+		omega  = quoRadical(addRadical(ratRadical(big.NewRat(-1, 1)), i3), ratRadical(big.NewRat(2, 1)))
+		omega2 = quoRadical(addRadical(ratRadical(big.NewRat(-1, 1)), negRadical(i3)), ratRadical(big.NewRat(2, 1)))
+	)
+	return []radical{
+		addRadical(shift, addRadical(plus, minus)),
+		addRadical(shift, addRadical(mulRadical(omega, plus), mulRadical(omega2, minus))),
+		addRadical(shift, addRadical(mulRadical(omega2, plus), mulRadical(omega, minus))),
+	}
+}
+
+// Find the roots of a quartic with Ferrari's method.
+func solveQuartic(ctx context.Context, p Polynomial) []radical {
+	var (
+		b   = new(big.Rat).Quo(p[3], p[4])
+		c   = new(big.Rat).Quo(p[2], p[4])
+		d   = new(big.Rat).Quo(p[1], p[4])
+		e   = new(big.Rat).Quo(p[0], p[4])
+		b2  = new(big.Rat).Mul(b, b)
+		two = ratRadical(big.NewRat(2, 1))
+	)
+
+	// Substituting x = y - b/4 gives the depressed quartic y⁴ + Py² + Qy + R
+	shift := ratRadical(new(big.Rat).Quo(new(big.Rat).Neg(b), big.NewRat(4, 1)))
+	pDepressed := new(big.Rat).Sub(c, new(big.Rat).Mul(big.NewRat(3, 8), b2))
+	q := new(big.Rat).Sub(d, new(big.Rat).Mul(big.NewRat(1, 2), new(big.Rat).Mul(b, c)))
+	q.Add(q, new(big.Rat).Mul(big.NewRat(1, 8), new(big.Rat).Mul(b2, b)))
+	r := new(big.Rat).Sub(e, new(big.Rat).Mul(big.NewRat(1, 4), new(big.Rat).Mul(b, d)))
+	r.Add(r, new(big.Rat).Mul(big.NewRat(1, 16), new(big.Rat).Mul(b2, c)))
+	r.Sub(r, new(big.Rat).Mul(big.NewRat(3, 256), new(big.Rat).Mul(b2, b2)))
+
+	var roots []radical
+	if q.Sign() == 0 {
+		// A biquadratic is a quadratic in y², so y = ±√((-P ± √(P² - 4R)) / 2)
+		discriminant := new(big.Rat).Sub(new(big.Rat).Mul(pDepressed, pDepressed), new(big.Rat).Mul(big.NewRat(4, 1), r))
+		root := sqrtRadical(ratRadical(discriminant))
+		for _, v := range []radical{root, negRadical(root)} {
+			y := sqrtRadical(quoRadical(addRadical(ratRadical(new(big.Rat).Neg(pDepressed)), v), two))
+			roots = append(roots, y, negRadical(y))
+		}
+	} else {
+		// Adding 2my² to both sides makes each side a perfect square when m is a root of the resolvent cubic
+		// 8m³ + 8Pm² + (2P² - 8R)m - Q² = 0, and then the quartic splits into two quadratics with s = √(2m):
+		//  y = (s ± √(-2P - 2m - 2Q/s)) / 2 and y = (-s ± √(-2P - 2m + 2Q/s)) / 2
+		resolvent := New(
+			new(big.Rat).Neg(new(big.Rat).Mul(q, q)),
+			new(big.Rat).Sub(new(big.Rat).Mul(big.NewRat(2, 1), new(big.Rat).Mul(pDepressed, pDepressed)), new(big.Rat).Mul(big.NewRat(8, 1), r)),
+			new(big.Rat).Mul(big.NewRat(8, 1), pDepressed),
+			big.NewRat(8, 1),
+		)
+
+		var m radical
+		if v, _, _ := findRationalRoot(ctx, resolvent); v != nil {
+			m = ratRadical(v)
+		} else {
+			m = solveCubic(resolvent)[0]
+		}
+
+		var (
+			s       = sqrtRadical(mulRadical(two, m))
+			base    = addRadical(ratRadical(new(big.Rat).Mul(big.NewRat(-2, 1), pDepressed)), mulRadical(ratRadical(big.NewRat(-2, 1)), m))
+			qOverS  = quoRadical(ratRadical(new(big.Rat).Mul(big.NewRat(2, 1), q)), s)
+			first   = sqrtRadical(addRadical(base, negRadical(qOverS)))
+			second  = sqrtRadical(addRadical(base, qOverS))
+			negated = negRadical(s)
+		)
+		roots = []radical{
+			quoRadical(addRadical(s, first), two),
+			quoRadical(addRadical(s, negRadical(first)), two),
+			quoRadical(addRadical(negated, second), two),
+			quoRadical(addRadical(negated, negRadical(second)), two),
+		}
+	}
+
+	for i, v := range roots {
+		roots[i] = addRadical(shift, v)
+	}
+	return roots
+}
+
+// Turn the roots of p that were written with radicals into real and complex roots with approximate values. Each
+// approximation is improved with Newton's method, to make up for the rounding errors of calculating it through the
+// radicals. No roots are returned if they couldn't be approximated, which happens when the coefficients are too large.
+func approximateRadicals(p Polynomial, roots []radical, multiplicity int) ([]Root, []Root) {
+	coefficients := make([]complex128, len(p))
+	for i, v := range p {
+		f, _ := v.Float64()
+		coefficients[i] = complex(f, 0)
+	}
+	eval := func(z complex128) (complex128, complex128) {
+		var value, derivative complex128
+		for i := len(coefficients) - 1; i >= 0; i-- {
+			derivative = derivative*z + value
+			value = value*z + coefficients[i]
+		}
+		return value, derivative
+	}
+
+	var realRoots, complexRoots []Root
+	for _, v := range roots {
+		node, z := v.expr, v.value
+		value, derivative := eval(z)
+		for i := 0; i < 3 && derivative != 0; i++ {
+			next := z - value/derivative
+			nextValue, nextDerivative := eval(next)
+			if cmplx.Abs(nextValue) >= cmplx.Abs(value) {
+				break
+			}
+			z, value, derivative = next, nextValue, nextDerivative
+		}
+		if cmplx.IsNaN(z) || cmplx.IsInf(z) {
+			return nil, nil
+		}
+
+		r := Root{Value: new(big.Rat).SetFloat64(real(z)), Radical: &node, Multiplicity: multiplicity}
+		if math.Abs(imag(z)) <= 1e-9*math.Max(1, cmplx.Abs(z)) {
+			realRoots = append(realRoots, r)
+		} else {
+			r.Imag = new(big.Rat).SetFloat64(imag(z))
+			complexRoots = append(complexRoots, r)
+		}
+	}
+
+	return realRoots, complexRoots
+}
@@ -0,0 +1,86 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/cmplx"
+)
+
+var _ = Describe("solving with radicals", func() {
+	// Evaluate the polynomial at a root, using the approximate value of the root
+	eval := func(p poly.Polynomial, r poly.Root) complex128 {
+		re, _ := r.Value.Float64()
+		var im float64
+		if r.Imag != nil {
+			im, _ = r.Imag.Float64()
+		}
+
+		var v complex128
+		for i := len(p) - 1; i >= 0; i-- {
+			c, _ := p[i].Float64()
+			v = v*complex(re, im) + complex(c, 0)
+		}
+		return v
+	}
+
+	DescribeTable("finding every root of a cubic or quartic",
+		func(p poly.Polynomial, real int) {
+			f, e := poly.FactorRadical(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(f.Result).To(Equal(poly.Radical))
+			Expect(f.Complex).To(BeTrue())
+
+			Expect(f.Roots()).To(HaveLen(real))
+			Expect(f.ComplexRoots()).To(HaveLen(p.Degree() - real))
+			for _, v := range append(f.Roots(), f.ComplexRoots()...) {
+				Expect(v.Radical).NotTo(BeNil())
+				Expect(cmplx.Abs(eval(p, v))).To(BeNumerically("<", 1e-9))
+			}
+		},
+		Entry("for a cubic with one real root", poly.Ints(-1, -1, 0, 1), 1),
+		Entry("for a cubic with three real roots", poly.Ints(1, -3, 0, 1), 3),
+		Entry("for a cubic that isn't monic", poly.Ints(3, 1, 5, 2), 1),
+		Entry("for a biquadratic", poly.Ints(1, 0, -10, 0, 1), 4),
+		Entry("for a quartic with two real roots", poly.Ints(-1, -1, 0, 0, 1), 2),
+		Entry("for a quartic without real roots", poly.Ints(1, 1, 0, 0, 1), 0),
+		Entry("for a quartic that isn't monic", poly.Ints(3, 1, 2, 5, 7), 0),
+	)
+
+	DescribeTable("writing roots with radicals",
+		func(p poly.Polynomial, expected []string) {
+			f, e := poly.FactorRadical(p)
+			Expect(e).NotTo(HaveOccurred())
+
+			var actual []string
+			for _, v := range f.Factors[0].Roots {
+				actual = append(actual, v.Radical.String())
+			}
+			for _, v := range f.Factors[0].Complex {
+				actual = append(actual, v.Radical.String())
+			}
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("for a pure cube root", poly.Ints(-2, 0, 0, 1), []string{"∛2", "((-1 + i√3) / 2)∛2", "((-1 - i√3) / 2)∛2"}),
+		Entry("for a cubic with a positive discriminant", poly.Ints(-1, -1, 0, 1), []string{
+			"∛((9 + √69) / 18) + ∛((9 - √69) / 18)",
+			"((-1 + i√3) / 2)∛((9 + √69) / 18) + ((-1 - i√3) / 2)∛((9 - √69) / 18)",
+			"((-1 - i√3) / 2)∛((9 + √69) / 18) + ((-1 + i√3) / 2)∛((9 - √69) / 18)",
+		}),
+		Entry("for a biquadratic", poly.Ints(1, 0, -10, 0, 1), []string{"√(5 + 2√6)", "-√(5 + 2√6)", "√(5 - 2√6)", "-√(5 - 2√6)"}),
+	)
+
+	It("should leave factors alone that can't be solved with radicals", func() {
+		f, e := poly.FactorRadical(poly.Ints(-1, -1, 0, 0, 0, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Not))
+	})
+
+	It("should be partial if some factors can't be solved with radicals", func() {
+		f, e := poly.FactorRadical(poly.Ints(-1, -1, 0, 0, 0, 1).Mul(poly.Ints(-2, 0, 0, 1)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Partial))
+		Expect(f.Roots()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,439 @@
+package poly
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// The kind of a step in the working of a factorization.
+type StepKind string
+
+const (
+	CommonFactorStep      StepKind = "common factor"      // Factoring out a common factor or the leading coefficient
+	IdentityStep          StepKind = "identity"           // Factoring a special product with its identity
+	SubstitutionStep      StepKind = "substitution"       // Factoring a polynomial in x^k by substituting u = x^k
+	RationalRootStep      StepKind = "rational root"      // Testing the candidates given by the rational root theorem
+	SyntheticDivisionStep StepKind = "synthetic division" // Dividing by the linear factor of a root
+	GroupingStep          StepKind = "grouping"           // Splitting the middle term of a quadratic and grouping
+	QuadraticFormulaStep  StepKind = "quadratic formula"  // Finding the roots of a quadratic with the quadratic formula
+	IntegerFactoringStep  StepKind = "integer factoring"  // Factoring over the integers when there are no rational roots
+	IrreducibleStep       StepKind = "irreducible"        // Finding that a factor can't be factored any further
+	CubicFormulaStep      StepKind = "cubic formula"      // Solving a cubic with Cardano's method
+	QuarticFormulaStep    StepKind = "quartic formula"    // Solving a quartic with Ferrari's method
+	NumericStep           StepKind = "numeric"            // Approximating roots with the Aberth-Ehrlich method
+)
+
+// The most candidates of each kind that a rational root step lists before leaving the rest out.
+const maxListedCandidates = 12
+
+// One step in the working of a factorization, described both in plain text and in LaTeX. The descriptions are only
+// written when they are asked for, since most factorizations never show their working.
+type Step struct {
+	Kind     StepKind
+	describe func(f stepFormat) string
+}
+
+// Describe the step in plain text.
+func (s Step) Text() string {
+	return s.describe(false)
+}
+
+// Describe the step in LaTeX, where mathematics is delimited by \( and \), or by \[ and \] when it is displayed on its
+// own line.
+func (s Step) LaTeX() string {
+	return s.describe(true)
+}
+
+// Formats the mathematics in the description of a step, either as plain text or as LaTeX.
+type stepFormat bool
+
+// Create a step from a function that describes it, which is called each time the step is described in a format.
+func newStep(kind StepKind, describe func(f stepFormat) string) Step {
+	return Step{Kind: kind, describe: describe}
+}
+
+// Choose between the plain text and the LaTeX version of something.
+func (f stepFormat) choose(text, latex string) string {
+	if f {
+		return latex
+	}
+
+	return text
+}
+
+// Delimit mathematics within the description.
+func (f stepFormat) math(s string) string {
+	if f {
+		return `\(` + s + `\)`
+	}
+
+	return s
+}
+
+func (f stepFormat) poly(p Polynomial) string {
+	return p.format("x", bool(f))
+}
+
+func (f stepFormat) rat(v *big.Rat) string {
+	if !bool(f) || v.IsInt() {
+		return v.RatString()
+	}
+
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf(`%s\frac{%s}{%s}`, sign, new(big.Int).Abs(v.Num()), v.Denom())
+}
+
+func (f stepFormat) surd(s Surd) string {
+	return s.format(bool(f))
+}
+
+func (f stepFormat) pm() string {
+	return f.choose("±", `\pm`)
+}
+
+func (f stepFormat) sqrt(s string) string {
+	if f {
+		return `\sqrt{` + s + `}`
+	} else if strings.ContainsAny(s, " /") || strings.HasPrefix(s, "-") {
+		return "√(" + s + ")"
+	}
+
+	return "√" + s
+}
+
+func (f stepFormat) frac(num, den string) string {
+	if f {
+		return `\frac{` + num + `}{` + den + `}`
+	} else if strings.Contains(num, " ") {
+		num = "(" + num + ")"
+	}
+
+	return num + " / " + den
+}
+
+// Format a monomial c·x^k.
+func (f stepFormat) monomial(c *big.Rat, k int) string {
+	m := make(Polynomial, k+1)
+	for i := range m {
+		m[i] = new(big.Rat)
+	}
+	m[k].Set(c)
+
+	return f.poly(m)
+}
+
+// Format a set of integers, leaving out the middle of long sets.
+func (f stepFormat) set(values []*big.Int) string {
+	var s []string
+	for i, v := range values {
+		if i == maxListedCandidates-1 && len(values) > maxListedCandidates {
+			s = append(s, f.choose("…", `\ldots`), values[len(values)-1].String())
+			break
+		}
+		s = append(s, v.String())
+	}
+
+	return f.choose("{", `\{`) + strings.Join(s, ", ") + f.choose("}", `\}`)
+}
+
+// Format the product of some factors of p, with whatever constant is needed to make the product equal to p.
+func (f stepFormat) product(p Polynomial, factors []Component, variable string) string {
+	var (
+		b        strings.Builder
+		constant = new(big.Rat).Set(p.Leading())
+	)
+	for _, v := range factors {
+		for i := 0; i < v.Multiplicity; i++ {
+			constant.Quo(constant, v.Polynomial.Leading())
+		}
+
+		factor := "(" + v.Polynomial.format(variable, bool(f)) + ")"
+		if v.Polynomial.Equal(Ints(0, 1)) {
+			factor = variable
+		}
+		if v.Multiplicity > 1 && f {
+			factor += fmt.Sprintf("^{%d}", v.Multiplicity)
+		} else if v.Multiplicity > 1 {
+			factor += fmt.Sprintf("^%d", v.Multiplicity)
+		}
+		b.WriteString(factor)
+	}
+
+	switch {
+	case constant.Cmp(big.NewRat(1, 1)) == 0:
+		return b.String()
+	case constant.Cmp(big.NewRat(-1, 1)) == 0:
+		return "-" + b.String()
+	case !bool(f) && !constant.IsInt():
+		return "(" + constant.RatString() + ")" + b.String()
+	}
+	return f.rat(constant) + b.String()
+}
+
+// Format p as the product of the given polynomials, each of which divides it once.
+func (f stepFormat) productOf(p Polynomial, factors ...Polynomial) string {
+	components := make([]Component, len(factors))
+	for i, v := range factors {
+		components[i] = Component{Polynomial: v, Multiplicity: 1}
+	}
+
+	return f.product(p, components, "x")
+}
+
+// Format the solutions of an equation in x as "x = a or x = b".
+func (f stepFormat) solutions(values []string) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = f.math("x = " + v)
+	}
+
+	return strings.Join(s, " or ")
+}
+
+// The step that factors out the common factor c·x^k of p, leaving q.
+func commonFactorStep(p Polynomial, c *big.Rat, k int, q Polynomial) Step {
+	return newStep(CommonFactorStep, func(f stepFormat) string {
+		gcf := f.monomial(c, k)
+		product := gcf + "(" + f.poly(q) + ")"
+		if gcf == "-1" {
+			product = "-(" + f.poly(q) + ")"
+		}
+		return fmt.Sprintf("Factor out the greatest common factor %s, so %s", f.math(gcf), f.math(f.poly(p)+" = "+product))
+	})
+}
+
+// The step that factors the leading coefficient out of p.
+func leadingCoefficientStep(p Polynomial) Step {
+	return newStep(CommonFactorStep, func(f stepFormat) string {
+		monic := p.Scale(new(big.Rat).Inv(p.Leading()))
+		return fmt.Sprintf("Factor out the leading coefficient %s, so %s", f.math(f.rat(p.Leading())), f.math(f.poly(p)+" = "+f.productOf(p, monic)))
+	})
+}
+
+// The formula for each identity in plain text and in LaTeX.
+var identityFormulas = map[Identity][2]string{
+	DifferenceOfSquares: {"a^2 - b^2 = (a - b)(a + b)", `a^{2} - b^{2} = (a - b)(a + b)`},
+	SumOfCubes:          {"a^3 + b^3 = (a + b)(a^2 - ab + b^2)", `a^{3} + b^{3} = (a + b)(a^{2} - ab + b^{2})`},
+	DifferenceOfCubes:   {"a^3 - b^3 = (a - b)(a^2 + ab + b^2)", `a^{3} - b^{3} = (a - b)(a^{2} + ab + b^{2})`},
+	SumOfPowers:         {"a^n + b^n = (a + b)(a^(n-1) - a^(n-2)b + ... + b^(n-1))", `a^{n} + b^{n} = (a + b)(a^{n-1} - a^{n-2}b + \cdots + b^{n-1})`},
+	DifferenceOfPowers:  {"a^n - b^n = (a - b)(a^(n-1) + a^(n-2)b + ... + b^(n-1))", `a^{n} - b^{n} = (a - b)(a^{n-1} + a^{n-2}b + \cdots + b^{n-1})`},
+	PerfectSquare:       {"a^2 + 2ab + b^2 = (a + b)^2", `a^{2} + 2ab + b^{2} = (a + b)^{2}`},
+	BinomialExpansion:   {"a^n + na^(n-1)b + ... + b^n = (a + b)^n", `\sum_{k=0}^{n} \binom{n}{k} a^{n-k} b^{k} = (a + b)^{n}`},
+}
+
+// The step that factors p into 'factors' with an identity, where a and b are the terms the identity is applied to, and
+// n is the power for the identities that have one.
+func identityStep(identity Identity, p, a Polynomial, b *big.Rat, n int, factors []Component) Step {
+	return newStep(IdentityStep, func(f stepFormat) string {
+		with := fmt.Sprintf("%s and %s", f.math("a = "+f.poly(a)), f.math("b = "+f.rat(b)))
+		if identity == SumOfPowers || identity == DifferenceOfPowers || identity == BinomialExpansion {
+			with = fmt.Sprintf("%s, %s and %s", f.math("a = "+f.poly(a)), f.math("b = "+f.rat(b)), f.math(fmt.Sprintf("n = %d", n)))
+		}
+
+		formula := f.choose(identityFormulas[identity][0], identityFormulas[identity][1])
+		return fmt.Sprintf("%s is a %s, %s, with %s, so %s", f.math(f.poly(p)), identity, f.math(formula), with, f.math(f.poly(p)+" = "+f.product(p, factors, "x")))
+	})
+}
+
+// The step that substitutes u = x^k into p to get q, whose factorization is g, and then substitutes back to get the
+// factors in 'back'.
+func substitutionStep(p Polynomial, k int, q Polynomial, g Factorization, back []Component) Step {
+	return newStep(SubstitutionStep, func(f stepFormat) string {
+		u := f.monomial(big.NewRat(1, 1), k)
+		return fmt.Sprintf("Substitute %s, so %s. Substituting %s back in gives %s",
+			f.math("u = "+u),
+			f.math(f.poly(p)+" = "+q.format("u", bool(f))+" = "+f.product(q, g.Factors, "u")),
+			f.math(u),
+			f.math(f.product(p, back, "x")),
+		)
+	})
+}
+
+// The step that tests the candidates given by the rational root theorem, which are ±num/den for every num in 'nums' and
+// den in 'dens', finding 'root', or nil if none of them are roots.
+func rationalRootStep(p Polynomial, root *big.Rat, nums, dens []*big.Int) Step {
+	return newStep(RationalRootStep, func(f stepFormat) string {
+		integers := p.scaleToIntegers()
+		candidates := fmt.Sprintf("By the rational root theorem, any rational root of %s is %s, where %s divides %s and %s divides %s, so %s and %s",
+			f.math(f.poly(p)),
+			f.math(f.pm()+f.frac("p", "q")),
+			f.math("p"), f.math(new(big.Int).Abs(integers[0]).String()),
+			f.math("q"), f.math(new(big.Int).Abs(integers[len(integers)-1]).String()),
+			f.math(f.choose("p ∈ ", `p \in `)+f.set(nums)),
+			f.math(f.choose("q ∈ ", `q \in `)+f.set(dens)),
+		)
+		if root == nil {
+			return candidates + ". None of them are roots"
+		}
+		return fmt.Sprintf("%s. Testing them shows that %s is a root", candidates, f.math("x = "+f.rat(root)))
+	})
+}
+
+// The step that divides p by (x - root) with synthetic division, leaving 'quotient'.
+func syntheticDivisionStep(p Polynomial, root *big.Rat, quotient Polynomial) Step {
+	return newStep(SyntheticDivisionStep, func(f stepFormat) string {
+		// The first row holds the coefficients, the second holds each result multiplied by the root, and the third holds
+		// the results, which are the coefficients of the quotient followed by the remainder
+		n := len(p) - 1
+		rows := [3][]*big.Rat{make([]*big.Rat, n+1), make([]*big.Rat, n+1), make([]*big.Rat, n+1)}
+		for i := 0; i <= n; i++ {
+			rows[0][i] = p[n-i]
+			if i < n {
+				rows[2][i] = quotient[n-1-i]
+			} else {
+				rows[2][i] = new(big.Rat)
+			}
+			if i > 0 {
+				rows[1][i] = new(big.Rat).Mul(rows[2][i-1], root)
+			}
+		}
+
+		cells := [3][]string{}
+		width := len(f.rat(root))
+		for r, row := range rows {
+			for _, v := range row {
+				s := ""
+				if v != nil {
+					s = f.rat(v)
+				}
+				cells[r] = append(cells[r], s)
+				if len(s) > width {
+					width = len(s)
+				}
+			}
+		}
+
+		var table string
+		if f {
+			table = fmt.Sprintf(` \[\begin{array}{r|%s} %s & %s \\ & %s \\ \hline & %s \end{array}\] `,
+				strings.Repeat("r", n+1), f.rat(root), strings.Join(cells[0], " & "), strings.Join(cells[1], " & "), strings.Join(cells[2], " & "))
+		} else {
+			pad := func(row []string) string {
+				s := make([]string, len(row))
+				for i, v := range row {
+					s[i] = fmt.Sprintf("%*s", width, v)
+				}
+				return strings.Join(s, "  ")
+			}
+			table = fmt.Sprintf("\n%*s | %s\n%*s | %s\n%s\n%*s   %s\n",
+				width, f.rat(root), pad(cells[0]),
+				width, "", pad(cells[1]),
+				strings.Repeat("-", width+3+(n+1)*(width+2)-2),
+				width, "", pad(cells[2]),
+			)
+		}
+
+		return fmt.Sprintf("Divide %s by %s with synthetic division:%sso %s",
+			f.math(f.poly(p)), f.math("("+f.poly(linear(root))+")"), table, f.math(f.poly(p)+" = "+f.productOf(p, linear(root), quotient)))
+	})
+}
+
+// The step that factors the quadratic p by finding two numbers m and n that multiply to ac and add to b.
+func groupingStep(p Polynomial, m, n *big.Rat, factors []Component) Step {
+	return newStep(GroupingStep, func(f stepFormat) string {
+		// The middle term is split into mx + nx
+		split := f.monomial(p[2], 2)
+		for i, v := range []*big.Rat{m, n, p[0]} {
+			if v.Sign() < 0 {
+				split += " - "
+			} else {
+				split += " + "
+			}
+			split += f.monomial(new(big.Rat).Abs(v), 1-i/2) // The constant term comes last
+		}
+
+		return fmt.Sprintf("Find two numbers that multiply to %s and add to %s, which are %s and %s. Splitting the middle term and grouping, %s",
+			f.math("ac = "+f.rat(new(big.Rat).Mul(p[0], p[2]))),
+			f.math("b = "+f.rat(p[1])),
+			f.math(f.rat(m)), f.math(f.rat(n)),
+			f.math(f.poly(p)+" = "+split+" = "+f.product(p, factors, "x")),
+		)
+	})
+}
+
+// The step that finds the roots of the quadratic p with the quadratic formula.
+func quadraticFormulaStep(p Polynomial, discriminant *big.Rat, roots []Root) Step {
+	return newStep(QuadraticFormulaStep, func(f stepFormat) string {
+		// The -b term is left out when b is 0
+		numerator := f.pm() + f.sqrt(f.rat(discriminant))
+		if p[1].Sign() != 0 {
+			numerator = f.rat(new(big.Rat).Neg(p[1])) + " " + f.pm() + " " + f.sqrt(f.rat(discriminant))
+		}
+		formula := fmt.Sprintf("x = %s = %s",
+			f.frac("-b "+f.pm()+" "+f.sqrt(f.choose("b^2 - 4ac", "b^{2} - 4ac")), "2a"),
+			f.frac(numerator, f.rat(new(big.Rat).Mul(big.NewRat(2, 1), p[2]))),
+		)
+		intro := fmt.Sprintf("Using the quadratic formula with %s, %s and %s, %s",
+			f.math("a = "+f.rat(p[2])), f.math("b = "+f.rat(p[1])), f.math("c = "+f.rat(p[0])), f.math(formula))
+
+		// Complex roots are written together as a ± bi
+		if len(roots) > 0 && roots[0].IsComplex() {
+			var (
+				imag = f.rat(new(big.Rat).Abs(roots[0].Imag))
+				re   string
+			)
+			if roots[0].Surd != nil {
+				s := *roots[0].Surd
+				s.Coefficient = new(big.Rat).Abs(s.Coefficient)
+				imag = f.surd(s)
+			}
+			if imag == "1" {
+				imag = ""
+			} else if !bool(f) && strings.ContainsAny(imag, "/√") {
+				imag = "(" + imag + ")"
+			}
+			if roots[0].Value.Sign() != 0 {
+				re = f.rat(roots[0].Value) + " " + f.pm() + " "
+			} else {
+				re = f.pm()
+			}
+			return fmt.Sprintf("%s. The discriminant is negative, so the roots are complex: %s", intro, f.math("x = "+re+imag+"i"))
+		}
+
+		var values []string
+		for _, v := range roots {
+			if v.Surd != nil {
+				values = append(values, f.surd(*v.Surd))
+			} else {
+				values = append(values, f.rat(v.Value))
+			}
+		}
+		return fmt.Sprintf("%s, so %s", intro, f.solutions(values))
+	})
+}
+
+// The step that factors p over the integers into 'factors'.
+func integerFactoringStep(p Polynomial, factors []Component) Step {
+	return newStep(IntegerFactoringStep, func(f stepFormat) string {
+		return fmt.Sprintf("Factoring over the integers by factoring modulo a prime and lifting the factors with Hensel's lemma gives %s",
+			f.math(f.poly(p)+" = "+f.product(p, factors, "x")))
+	})
+}
+
+// The step that finds p can't be factored any further.
+func irreducibleStep(p Polynomial) Step {
+	return newStep(IrreducibleStep, func(f stepFormat) string {
+		if p.Degree() <= 3 {
+			return fmt.Sprintf("%s has no rational roots, and a polynomial of degree %d without rational roots can't be factored over the rationals", f.math(f.poly(p)), p.Degree())
+		}
+		return fmt.Sprintf("%s can't be factored over the integers, so it is irreducible over the rationals", f.math(f.poly(p)))
+	})
+}
+
+// The step that solves p = 0 with Cardano's or Ferrari's method, or numerically to the given number of digits.
+func solveStep(kind StepKind, p Polynomial, digits int) Step {
+	return newStep(kind, func(f stepFormat) string {
+		equation := f.math(f.poly(p) + " = 0")
+		switch kind {
+		case CubicFormulaStep:
+			return fmt.Sprintf("Solve %s with Cardano's method", equation)
+		case QuarticFormulaStep:
+			return fmt.Sprintf("Solve %s with Ferrari's method", equation)
+		}
+		return fmt.Sprintf("Approximate the roots of %s to %d digits with the Aberth-Ehrlich method", equation, digits)
+	})
+}
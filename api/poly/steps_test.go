@@ -0,0 +1,74 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("the working of a factorization", func() {
+	kinds := func(f poly.Factorization) []poly.StepKind {
+		var r []poly.StepKind
+		for _, v := range f.Steps {
+			r = append(r, v.Kind)
+		}
+		return r
+	}
+
+	DescribeTable("the kinds of steps taken",
+		func(p poly.Polynomial, expected []poly.StepKind) {
+			f, e := poly.Factor(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(kinds(f)).To(Equal(expected))
+
+			for _, v := range f.Steps {
+				Expect(v.Text()).NotTo(BeEmpty())
+				Expect(v.LaTeX()).NotTo(BeEmpty())
+			}
+		},
+		Entry("for a trinomial that can be grouped", poly.Ints(10, 7, 1), []poly.StepKind{poly.GroupingStep}),
+		Entry("for a trinomial with irrational roots", poly.Ints(-1, -2, 1), []poly.StepKind{poly.QuadraticFormulaStep}),
+		Entry("for a cubic with a common factor", poly.Ints(-12, 22, -12, 2), []poly.StepKind{poly.CommonFactorStep, poly.RationalRootStep, poly.SyntheticDivisionStep, poly.GroupingStep}),
+		Entry("for a polynomial without rational roots", poly.Ints(1, 1, 0, 0, 1), []poly.StepKind{poly.RationalRootStep, poly.IrreducibleStep}),
+		Entry("for a product of quadratics", poly.Ints(2, 1, 2, 0, 1), []poly.StepKind{poly.RationalRootStep, poly.IntegerFactoringStep, poly.QuadraticFormulaStep, poly.QuadraticFormulaStep}),
+		Entry("for a special product", poly.Ints(-9, 0, 4), []poly.StepKind{poly.CommonFactorStep, poly.IdentityStep}),
+		Entry("for a polynomial in x^2", poly.Ints(6, 0, -5, 0, 1), []poly.StepKind{poly.SubstitutionStep, poly.QuadraticFormulaStep, poly.QuadraticFormulaStep}),
+		Entry("for a monomial", poly.Ints(0, 0, 6), []poly.StepKind(nil)),
+	)
+
+	It("should describe each step in plain text and in LaTeX", func() {
+		f, e := poly.Factor(poly.Ints(-12, 22, -12, 2))
+		Expect(e).NotTo(HaveOccurred())
+
+		Expect(f.Steps[0].Text()).To(Equal("Factor out the greatest common factor 2, so 2x^3 - 12x^2 + 22x - 12 = 2(x^3 - 6x^2 + 11x - 6)"))
+		Expect(f.Steps[0].LaTeX()).To(Equal(`Factor out the greatest common factor \(2\), so \(2x^{3} - 12x^{2} + 22x - 12 = 2(x^{3} - 6x^{2} + 11x - 6)\)`))
+		Expect(f.Steps[1].Text()).To(HavePrefix("By the rational root theorem, any rational root of x^3 - 6x^2 + 11x - 6 is ±p / q, where p divides 6 and q divides 1, so p ∈ {1, 2, 3, 6} and q ∈ {1}."))
+	})
+
+	It("should show synthetic division as a table", func() {
+		f, e := poly.Factor(poly.Ints(-6, 11, -6, 1).Mul(poly.Ints(1, 0, 1)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Steps[1].Kind).To(Equal(poly.SyntheticDivisionStep))
+		Expect(f.Steps[1].LaTeX()).To(ContainSubstring(`\begin{array}{r|rrrrrr}`))
+		Expect(f.Steps[1].LaTeX()).To(ContainSubstring(`\hline`))
+	})
+
+	It("should write complex roots in a ± bi form", func() {
+		f, e := poly.Factor(poly.Ints(5, 2, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Steps).To(HaveLen(1))
+		Expect(f.Steps[0].Text()).To(HaveSuffix("The discriminant is negative, so the roots are complex: x = -1 ± 2i"))
+		Expect(f.Steps[0].LaTeX()).To(HaveSuffix(`\(x = -1 \pm 2i\)`))
+	})
+
+	It("should record how roots were found in radical and numeric modes", func() {
+		f, e := poly.FactorRadical(poly.Ints(-2, 0, 0, 1))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(kinds(f)).To(ContainElement(poly.CubicFormulaStep))
+
+		f, e = poly.FactorNumeric(poly.Ints(-1, -1, 0, 0, 0, 1), 10)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(kinds(f)).To(ContainElement(poly.NumericStep))
+	})
+})
@@ -0,0 +1,233 @@
+package poly
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// An interval of the real line that isolates a single real root. The root lies strictly between Lower and Upper, unless
+// they are equal, in which case the root is exactly that value.
+type Interval struct {
+	Lower, Upper *big.Rat
+}
+
+// The width of the interval.
+func (i Interval) Width() *big.Rat {
+	return new(big.Rat).Sub(i.Upper, i.Lower)
+}
+
+// Check if the interval is a single point, which means that the root it contains is known exactly.
+func (i Interval) IsExact() bool {
+	return i.Lower.Cmp(i.Upper) == 0
+}
+
+// The Sturm sequence of the polynomial: p, p', and then the negated remainder of dividing the previous two, until the
+// remainder is 0.
+func (p Polynomial) SturmSequence() []Polynomial {
+	return p.sturmSequence(context.Background())
+}
+
+// Calculate the Sturm sequence of the polynomial like SturmSequence. Returns nil if the context is done.
+func (p Polynomial) sturmSequence(ctx context.Context) []Polynomial {
+	r := []Polynomial{p.trim(), p.Derivative()}
+	for r[len(r)-1].Degree() > 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		_, remainder := r[len(r)-2].DivMod(r[len(r)-1])
+		if remainder.Degree() < 0 {
+			break
+		}
+		r = append(r, remainder.Scale(big.NewRat(-1, 1)))
+	}
+
+	return r
+}
+
+// Count the distinct real roots of the polynomial using Sturm's theorem.
+func (p Polynomial) CountRealRoots() int {
+	n, _ := p.CountRealRootsContext(context.Background())
+	return n
+}
+
+// Count the distinct real roots of the polynomial like CountRealRoots, giving up with the context's error when it is
+// done.
+func (p Polynomial) CountRealRootsContext(ctx context.Context) (int, error) {
+	if p.Degree() < 1 {
+		return 0, nil
+	}
+
+	sequence := p.sturmSequence(ctx)
+	if e := ctx.Err(); e != nil {
+		return 0, e
+	}
+
+	// At ±∞ the sign of each polynomial in the sequence is the sign of its leading coefficient, adjusted for the parity
+	// of its degree at -∞
+	var negative, positive []int
+	for _, v := range sequence {
+		sign := v.Leading().Sign()
+		positive = append(positive, sign)
+		if v.Degree()%2 == 1 {
+			sign = -sign
+		}
+		negative = append(negative, sign)
+	}
+
+	return signChanges(negative) - signChanges(positive), nil
+}
+
+// Upper bounds on the number of positive and negative real roots, counted with multiplicity, from Descartes' rule of
+// signs. Each bound is the number of sign changes between consecutive nonzero coefficients of p(x) and p(-x), and the
+// true number of roots differs from it by an even number.
+func (p Polynomial) DescartesBounds() (positive, negative int) {
+	var signs, negatedSigns []int
+	for i, v := range p {
+		signs = append(signs, v.Sign())
+		if i%2 == 1 {
+			negatedSigns = append(negatedSigns, -v.Sign())
+		} else {
+			negatedSigns = append(negatedSigns, v.Sign())
+		}
+	}
+
+	return signChanges(signs), signChanges(negatedSigns)
+}
+
+// Find disjoint intervals with rational endpoints that each contain exactly one distinct real root of the polynomial,
+// in ascending order. Intervals are found by repeatedly halving an interval that contains every root, and using Sturm's
+// theorem to count the roots in each half.
+func (p Polynomial) IsolateRoots() ([]Interval, error) {
+	return p.IsolateRootsContext(context.Background())
+}
+
+// Isolate the real roots of the polynomial like IsolateRoots, giving up with the context's error when it is done.
+func (p Polynomial) IsolateRootsContext(ctx context.Context) ([]Interval, error) {
+	p = p.trim()
+	if p.Degree() < 1 {
+		return nil, ErrConstant
+	}
+
+	// Repeated roots don't change which intervals are found, and removing them makes refining intervals easier
+	g := gcd(ctx, p, p.Derivative())
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+	p, _ = p.DivMod(g)
+	sequence := p.sturmSequence(ctx)
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	// Every root is strictly inside (-M, M) by Cauchy's bound, where M = 1 + max|aᵢ / aₙ|
+	bound := new(big.Rat)
+	for _, v := range p[:len(p)-1] {
+		if v := new(big.Rat).Abs(new(big.Rat).Quo(v, p.Leading())); v.Cmp(bound) > 0 {
+			bound = v
+		}
+	}
+	bound.Add(bound, big.NewRat(1, 1))
+
+	var (
+		r     []Interval
+		stack = []Interval{{new(big.Rat).Neg(bound), bound}}
+	)
+	for len(stack) > 0 {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch n := sturmCount(sequence, i.Lower) - sturmCount(sequence, i.Upper); {
+		case n == 1:
+			r = append(r, i)
+		case n > 1:
+			// The interval is split somewhere other than at a root, so that the endpoints are never roots
+			mid := new(big.Rat).Add(i.Lower, i.Upper)
+			mid.Quo(mid, big.NewRat(2, 1))
+			for step := new(big.Rat).Quo(i.Width(), big.NewRat(4, 1)); p.Eval(mid).Sign() == 0; step.Quo(step, big.NewRat(2, 1)) {
+				mid.Add(mid, step)
+			}
+
+			// The upper half goes on the stack first so that the intervals come out in ascending order
+			stack = append(stack, Interval{mid, i.Upper}, Interval{i.Lower, mid})
+		}
+	}
+
+	return r, nil
+}
+
+// Shrink an interval isolating a simple root of the polynomial until it is no wider than 'width', by bisection. If a
+// midpoint turns out to be the root, the interval shrinks to that point.
+func (p Polynomial) RefineInterval(i Interval, width *big.Rat) (Interval, error) {
+	return p.RefineIntervalContext(context.Background(), i, width)
+}
+
+// Shrink an interval isolating a simple root of the polynomial like RefineInterval, giving up with the context's error
+// when it is done.
+func (p Polynomial) RefineIntervalContext(ctx context.Context, i Interval, width *big.Rat) (Interval, error) {
+	if width.Sign() <= 0 {
+		return Interval{}, errors.New("poly: width must be positive")
+	}
+
+	// The root is simple, so the polynomial has opposite signs at the two endpoints
+	g := gcd(ctx, p, p.Derivative())
+	if e := ctx.Err(); e != nil {
+		return Interval{}, e
+	}
+	p, _ = p.DivMod(g)
+	lower, upper := new(big.Rat).Set(i.Lower), new(big.Rat).Set(i.Upper)
+	lowerSign := p.Eval(lower).Sign()
+	for new(big.Rat).Sub(upper, lower).Cmp(width) > 0 {
+		if e := ctx.Err(); e != nil {
+			return Interval{}, e
+		}
+
+		mid := new(big.Rat).Add(lower, upper)
+		mid.Quo(mid, big.NewRat(2, 1))
+
+		switch sign := p.Eval(mid).Sign(); {
+		case sign == 0:
+			return Interval{mid, new(big.Rat).Set(mid)}, nil
+		case sign == lowerSign:
+			lower = mid
+		default:
+			upper = mid
+		}
+	}
+
+	return Interval{lower, upper}, nil
+}
+
+// The number of sign changes in the Sturm sequence at x.
+func sturmCount(sequence []Polynomial, x *big.Rat) int {
+	signs := make([]int, len(sequence))
+	for i, v := range sequence {
+		signs[i] = v.Eval(x).Sign()
+	}
+
+	return signChanges(signs)
+}
+
+// The number of times consecutive signs change between positive and negative, ignoring zeros.
+func signChanges(signs []int) int {
+	var (
+		r    int
+		last int
+	)
+	for _, v := range signs {
+		if v == 0 {
+			continue
+		}
+		if last != 0 && v != last {
+			r++
+		}
+		last = v
+	}
+
+	return r
+}
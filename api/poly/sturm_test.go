@@ -0,0 +1,114 @@
+package poly_test
+
+import (
+	"context"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+	"math/rand"
+	"time"
+)
+
+var _ = Describe("isolating real roots", func() {
+	DescribeTable("counting real roots",
+		func(p poly.Polynomial, expected int) {
+			Expect(p.CountRealRoots()).To(Equal(expected))
+		},
+		Entry("for a quadratic with two roots", poly.Ints(-2, 0, 1), 2),
+		Entry("for a quadratic without roots", poly.Ints(1, 0, 1), 0),
+		Entry("for a quintic with one root", poly.Ints(-1, -1, 0, 0, 0, 1), 1),
+		Entry("for repeated roots", poly.Ints(-1, 1).Pow(3).Mul(poly.Ints(2, 1)), 2),
+		Entry("for a cubic with three roots", poly.Ints(1, -3, 0, 1), 3),
+	)
+
+	DescribeTable("bounding roots with Descartes' rule of signs",
+		func(p poly.Polynomial, positive, negative int) {
+			a, b := p.DescartesBounds()
+			Expect(a).To(Equal(positive))
+			Expect(b).To(Equal(negative))
+		},
+		Entry("for x^3 - 3x + 1", poly.Ints(1, -3, 0, 1), 2, 1),
+		Entry("for x^5 - x - 1", poly.Ints(-1, -1, 0, 0, 0, 1), 1, 2),
+		Entry("for x^2 + 1", poly.Ints(1, 0, 1), 0, 0),
+	)
+
+	DescribeTable("finding isolating intervals",
+		func(p poly.Polynomial) {
+			intervals, e := p.IsolateRoots()
+			Expect(e).NotTo(HaveOccurred())
+			Expect(intervals).To(HaveLen(p.CountRealRoots()))
+
+			for i, v := range intervals {
+				// Each interval contains a sign change of the square-free part, and doesn't overlap the next one
+				Expect(v.Lower.Cmp(v.Upper)).To(BeNumerically("<", 0))
+				if i > 0 {
+					Expect(intervals[i-1].Upper.Cmp(v.Lower)).To(BeNumerically("<=", 0))
+				}
+			}
+		},
+		Entry("for a cubic with three roots", poly.Ints(1, -3, 0, 1)),
+		Entry("for roots that are very close together", poly.Ints(9999, -20000, 10000)),
+		Entry("for rational roots", poly.Ints(-6, 11, -6, 1)),
+		Entry("for repeated roots", poly.Ints(-1, 1).Pow(3).Mul(poly.Ints(2, 1))),
+	)
+
+	It("should refine an interval to the requested width", func() {
+		intervals, e := poly.Ints(-2, 0, 1).IsolateRoots()
+		Expect(e).NotTo(HaveOccurred())
+		Expect(intervals).To(HaveLen(2))
+
+		width := big.NewRat(1, 1000000)
+		refined, e := poly.Ints(-2, 0, 1).RefineInterval(intervals[1], width)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(refined.Width().Cmp(width)).To(BeNumerically("<=", 0))
+		Expect(refined.Lower.FloatString(5)).To(Equal("1.41421"))
+		Expect(refined.Upper.FloatString(5)).To(Equal("1.41421"))
+	})
+
+	It("should shrink an interval to a point when it finds a rational root", func() {
+		p := poly.Ints(-1, 1).Mul(poly.Ints(1, 1)).Mul(poly.Ints(-3, 1))
+		intervals, e := p.IsolateRoots()
+		Expect(e).NotTo(HaveOccurred())
+		Expect(intervals).To(HaveLen(3))
+
+		refined, e := p.RefineInterval(intervals[2], big.NewRat(1, 1e9))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(refined.IsExact()).To(BeTrue())
+		Expect(refined.Lower.RatString()).To(Equal("3"))
+	})
+
+	It("should reject constants and invalid widths", func() {
+		_, e := poly.Ints(5).IsolateRoots()
+		Expect(e).To(Equal(poly.ErrConstant))
+		_, e = poly.Ints(-2, 0, 1).RefineInterval(poly.Interval{Lower: big.NewRat(1, 1), Upper: big.NewRat(2, 1)}, new(big.Rat))
+		Expect(e).To(HaveOccurred())
+	})
+
+	It("should give up soon after the deadline for a large polynomial", func() {
+		rng := rand.New(rand.NewSource(1))
+		coefficients := make([]int64, 401)
+		for i := range coefficients {
+			coefficients[i] = rng.Int63n(2001) - 1000
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, e := poly.Ints(coefficients...).CountRealRootsContext(ctx)
+		Expect(e).To(Equal(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("should give up when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, e := poly.Ints(-2, 0, 1).IsolateRootsContext(ctx)
+		Expect(e).To(Equal(context.Canceled))
+		_, e = poly.Ints(-2, 0, 1).CountRealRootsContext(ctx)
+		Expect(e).To(Equal(context.Canceled))
+		_, e = poly.Ints(-2, 0, 1).RefineIntervalContext(ctx, poly.Interval{Lower: big.NewRat(1, 1), Upper: big.NewRat(2, 1)}, big.NewRat(1, 1000))
+		Expect(e).To(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,220 @@
+package poly
+
+import (
+	"context"
+	"math/big"
+)
+
+// Factor a polynomial with a nonzero constant term, using an identity if it is a special product, or substitution when
+// it is a polynomial in x^k for some k > 1.
+func factorInForm(ctx context.Context, p Polynomial) *Factorization {
+	if f := factorSpecialProduct(ctx, p); f != nil {
+		return f
+	}
+	if k := exponentGCD(p); k > 1 && p.Degree() > 2 {
+		return factorSubstitution(ctx, p, k)
+	}
+
+	return factorDirect(ctx, p)
+}
+
+// Factor a polynomial with a nonzero constant term without substitution.
+func factorDirect(ctx context.Context, p Polynomial) *Factorization {
+	switch p.Degree() {
+	case 1:
+		root := new(big.Rat).Quo(new(big.Rat).Neg(p[0]), p[1])
+		return &Factorization{Result: Full, Factors: []Component{{Polynomial: linear(root), Multiplicity: 1, Roots: []Root{{Value: root, Exact: true}}}}}
+	case 2:
+		return factorTrinomial(p)
+	default:
+		return factorPolynomial(ctx, p)
+	}
+}
+
+// Factor a polynomial p(x) = q(x^k) by factoring q(u) with the substitution u = x^k, and then factoring each of its
+// factors again after substituting x^k back in.
+//  x^4 - 5x^2 + 4 -> (u - 1)(u - 4) -> (x^2 - 1)(x^2 - 4) -> (x - 1)(x + 1)(x - 2)(x + 2)
+func factorSubstitution(ctx context.Context, p Polynomial, k int) *Factorization {
+	q := p.compress(k)
+	g, e := FactorContext(ctx, q)
+	if e != nil {
+		return nil
+	}
+
+	var (
+		components []Component
+		back       []Component
+		steps      []Step
+	)
+	for _, c := range g.Factors {
+		// Linear factors become binomials, which have identities of their own. Any other factor is irreducible in u,
+		// so substituting again would only find it again.
+		var f *Factorization
+		if c.Polynomial.Degree() == 1 {
+			f = factorBinomial(ctx, k, c.Roots[0].Value)
+		} else {
+			f = factorDirect(ctx, c.Polynomial.expand(k))
+		}
+		if f == nil {
+			return nil
+		}
+
+		for _, v := range f.Factors {
+			v.Multiplicity *= c.Multiplicity
+			components = append(components, v)
+		}
+		back = append(back, Component{Polynomial: c.Polynomial.expand(k), Multiplicity: c.Multiplicity})
+		steps = append(steps, f.Steps...)
+	}
+
+	return &Factorization{
+		Result:  resultOf(components),
+		Factors: components,
+		Steps:   append([]Step{substitutionStep(p, k, q, g, back)}, steps...),
+	}
+}
+
+// Decide how completely a list of factors factors the polynomial. Any factor without real roots leaves the polynomial
+// only partially factored, and the polynomial is not factored at all if that factor is the whole polynomial.
+func resultOf(components []Component) Result {
+	r := Full
+	for _, v := range components {
+		if len(v.Roots) == 0 {
+			r = Partial
+		} else if v.Polynomial.Degree() > 1 && r == Full {
+			r = Quadratic
+		}
+	}
+	if len(components) == 1 && components[0].Multiplicity == 1 && len(components[0].Roots) == 0 {
+		r = Not
+	}
+
+	return r
+}
+
+// Factor the binomial x^k - r, where r is nonzero, using the difference of squares, the sum and difference of cubes,
+// and the sum and difference of powers where they apply. The identity applied first is recorded in the factorization.
+// Returns nil if factoring fails.
+//  factorBinomial(ctx, 6, 64) -> (x^3 - 8)(x^3 + 8) -> (x - 2)(x^2 + 2x + 4)(x + 2)(x^2 - 2x + 4)
+func factorBinomial(ctx context.Context, k int, r *big.Rat) *Factorization {
+	p := make(Polynomial, k+1)
+	for i := range p {
+		p[i] = new(big.Rat)
+	}
+	p[0].Neg(r)
+	p[k].SetInt64(1)
+
+	var (
+		square   *big.Rat
+		isSquare bool
+		cube, _  = cbrtRat(r)
+		root, _  = rootRat(r, k)
+	)
+	if r.Sign() > 0 {
+		square, isSquare = sqrtRat(r)
+	}
+
+	// Each identity gives some factors, which are then factored further
+	var (
+		identity Identity
+		a        Polynomial // The terms a and b that the identity is applied to
+		b        *big.Rat
+		factors  []Polynomial
+		parts    []*Factorization
+	)
+	switch {
+	case k == 1:
+		return factorDirect(ctx, p)
+	case k%2 == 0 && isSquare:
+		// x^2m - s² = (x^m - s)(x^m + s)
+		m := k / 2
+		identity, a, b = DifferenceOfSquares, Ints(0, 1).expand(m), square
+		factors = []Polynomial{linear(square).expand(m), linear(new(big.Rat).Neg(square)).expand(m)}
+		parts = []*Factorization{factorBinomial(ctx, m, square), factorBinomial(ctx, m, new(big.Rat).Neg(square))}
+	case k%3 == 0 && cube != nil:
+		// x^3m - c³ = (x^m - c)(x^2m + cx^m + c²), which is the sum of cubes when c is negative
+		m := k / 3
+		identity, a, b = DifferenceOfCubes, Ints(0, 1).expand(m), cube
+		if cube.Sign() < 0 {
+			identity, b = SumOfCubes, new(big.Rat).Neg(cube)
+		}
+		rest := Polynomial{new(big.Rat).Mul(cube, cube), new(big.Rat).Set(cube), big.NewRat(1, 1)}.expand(m)
+		factors = []Polynomial{linear(cube).expand(m), rest}
+		parts = []*Factorization{factorBinomial(ctx, m, cube), factorInForm(ctx, rest)}
+	case root != nil:
+		// x^n - b^n = (x - b)(x^(n-1) + bx^(n-2) + ... + b^(n-1)), which is the sum of powers when b is negative
+		identity, a, b = DifferenceOfPowers, Ints(0, 1), root
+		if root.Sign() < 0 {
+			identity, b = SumOfPowers, new(big.Rat).Neg(root)
+		}
+		rest := make(Polynomial, k)
+		for i := range rest {
+			rest[k-1-i] = new(big.Rat).SetInt64(1)
+			for j := 0; j < i; j++ {
+				rest[k-1-i].Mul(rest[k-1-i], root)
+			}
+		}
+		factors = []Polynomial{linear(root), rest}
+		parts = []*Factorization{factorDirect(ctx, linear(root)), factorInForm(ctx, rest)}
+	default:
+		return factorDirect(ctx, p)
+	}
+
+	components := make([]Component, len(factors))
+	for i, v := range factors {
+		components[i] = Component{Polynomial: v, Multiplicity: 1}
+	}
+	f := &Factorization{Identity: identity, Steps: []Step{identityStep(identity, p, a, b, k, components)}}
+	for _, v := range parts {
+		if v == nil {
+			return nil
+		}
+		f.Factors = append(f.Factors, v.Factors...)
+		f.Steps = append(f.Steps, v.Steps...)
+	}
+	f.Result = resultOf(f.Factors)
+
+	return f
+}
+
+// The greatest common divisor of the exponents of the nonzero terms of the polynomial, or 0 if it is a constant.
+func exponentGCD(p Polynomial) int {
+	var r int
+	for i, v := range p {
+		if v.Sign() == 0 {
+			continue
+		}
+
+		// Euclid's algorithm
+		a, b := r, i
+		for b != 0 {
+			a, b = b, a%b
+		}
+		r = a
+	}
+
+	return r
+}
+
+// Find q such that p(x) = q(x^k), where every exponent of p is a multiple of k.
+func (p Polynomial) compress(k int) Polynomial {
+	r := make(Polynomial, (len(p)-1)/k+1)
+	for i := range r {
+		r[i] = new(big.Rat).Set(p[i*k])
+	}
+
+	return r
+}
+
+// Substitute x^k into the polynomial.
+func (p Polynomial) expand(k int) Polynomial {
+	r := make(Polynomial, (len(p)-1)*k+1)
+	for i := range r {
+		r[i] = new(big.Rat)
+	}
+	for i, v := range p {
+		r[i*k].Set(v)
+	}
+
+	return r
+}
@@ -0,0 +1,101 @@
+package poly
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/expr"
+	"math/big"
+)
+
+// An exact number of the form a + b√n, where a and b are rational and n is an integer without any square factors that
+// NewSurd could find. Roots found with the quadratic formula are always of this form.
+type Surd struct {
+	Rational    *big.Rat // a
+	Coefficient *big.Rat // b
+	Radicand    *big.Int // n, which is always greater than 1
+}
+
+// The largest number whose square is divided out of a radicand when simplifying a surd. Finding every square factor
+// would mean factoring the radicand completely, which can take far too long.
+const maxSquareRoot = 1 << 16
+
+// Create the surd a + b√r in simplified form, where r is a positive rational that isn't a perfect square. Square factors
+// are moved out of the radical by trial division up to the square of maxSquareRoot, and the denominator of r is
+// rationalised.
+//  NewSurd(-5, 1, 27) -> -5 + 3√3
+//  NewSurd(0, 1, 1/2) -> √2 / 2
+func NewSurd(a, b, r *big.Rat) Surd {
+	// √(p/q) = √(pq) / q, and pq = s²n where n has no square factors, so √(p/q) = (s/q)√n
+	var (
+		n       = new(big.Int).Mul(r.Num(), r.Denom())
+		outside = big.NewInt(1)
+		square  = new(big.Int)
+		q, m    = new(big.Int), new(big.Int)
+	)
+	for i := int64(2); i <= maxSquareRoot && square.SetInt64(i*i).Cmp(n) <= 0; i++ {
+		// Squares of composite numbers are already gone by the time they are tried, so only primes are divided out
+		for q.QuoRem(n, square, m); m.Sign() == 0; q.QuoRem(n, square, m) {
+			outside.Mul(outside, big.NewInt(i))
+			n.Set(q)
+		}
+	}
+
+	coefficient := new(big.Rat).SetFrac(outside, r.Denom())
+	return Surd{
+		Rational:    new(big.Rat).Set(a),
+		Coefficient: coefficient.Mul(coefficient, b),
+		Radicand:    n,
+	}
+}
+
+// Approximate the value of the surd.
+func (s Surd) Approximate() *big.Rat {
+	r := approximateSqrt(new(big.Rat).SetInt(s.Radicand))
+	return r.Mul(r, s.Coefficient).Add(r, s.Rational)
+}
+
+// Format the surd over a single reduced denominator.
+//  NewSurd(1/2, -1/2, 3).String() -> "(1 - √3) / 2"
+func (s Surd) String() string {
+	return s.format(false)
+}
+
+// Format the surd over a single reduced denominator as LaTeX.
+//  NewSurd(1/2, -1/2, 3).LaTeX() -> "\frac{1 - \sqrt{3}}{2}"
+func (s Surd) LaTeX() string {
+	return s.format(true)
+}
+
+// Format the surd either as plain text or as LaTeX.
+func (s Surd) format(latex bool) string {
+	if latex {
+		return expr.LaTeX.Write(s.Expr())
+	}
+
+	return s.Expr().String()
+}
+
+// The expression tree of the surd over a single reduced denominator.
+func (s Surd) Expr() expr.Node {
+	// Put both parts over their lowest common denominator
+	lcd := new(big.Int).GCD(nil, nil, s.Rational.Denom(), s.Coefficient.Denom())
+	lcd.Quo(new(big.Int).Mul(s.Rational.Denom(), s.Coefficient.Denom()), lcd)
+
+	var (
+		a = new(big.Int).Mul(s.Rational.Num(), new(big.Int).Quo(lcd, s.Rational.Denom()))
+		b = new(big.Int).Mul(s.Coefficient.Num(), new(big.Int).Quo(lcd, s.Coefficient.Denom()))
+	)
+
+	// A coefficient of 1 is implied
+	radical := expr.Product(expr.Int(new(big.Int).Abs(b)), expr.Sqrt(expr.Int(s.Radicand)))
+	if b.Sign() < 0 {
+		radical = expr.Neg(radical)
+	}
+
+	var numerator expr.Node
+	if a.Sign() == 0 {
+		numerator = radical
+	} else {
+		numerator = expr.Sum(expr.Int(a), radical)
+	}
+
+	return expr.Frac(numerator, expr.Int(lcd))
+}
@@ -0,0 +1,39 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/big"
+	"time"
+)
+
+var _ = Describe("surds", func() {
+	DescribeTable("simplifying and formatting",
+		func(a, b, r *big.Rat, expected string) {
+			Expect(poly.NewSurd(a, b, r).String()).To(Equal(expected))
+		},
+		Entry("with a square factor in the radical", big.NewRat(-5, 1), big.NewRat(1, 1), big.NewRat(27, 1), "-5 + 3√3"),
+		Entry("with a square-free radical", big.NewRat(0, 1), big.NewRat(1, 1), big.NewRat(6, 1), "√6"),
+		Entry("with a negative coefficient", big.NewRat(2, 1), big.NewRat(-3, 1), big.NewRat(5, 1), "2 - 3√5"),
+		Entry("with a fraction in the radical", big.NewRat(0, 1), big.NewRat(1, 1), big.NewRat(1, 2), "√2 / 2"),
+		Entry("with a common denominator", big.NewRat(-10, 4), big.NewRat(1, 4), big.NewRat(12, 1), "(-5 + √3) / 2"),
+		Entry("with different denominators", big.NewRat(1, 3), big.NewRat(-1, 2), big.NewRat(2, 1), "(2 - 3√2) / 6"),
+		Entry("with a large square factor", big.NewRat(0, 1), big.NewRat(1, 1), big.NewRat(65521*65521*3, 1), "65521√3"),
+	)
+
+	It("should not try to factor a large radicand completely", func() {
+		r, _ := new(big.Rat).SetString("30000000000018200000000002759")
+
+		start := time.Now()
+		s := poly.NewSurd(new(big.Rat), big.NewRat(1, 1), r)
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(s.String()).To(Equal("√30000000000018200000000002759"))
+	})
+
+	It("should be approximated correctly", func() {
+		v, _ := poly.NewSurd(big.NewRat(-5, 1), big.NewRat(1, 1), big.NewRat(27, 1)).Approximate().Float64()
+		Expect(v).To(BeNumerically("~", 0.19615, 1e-5))
+	})
+})
@@ -0,0 +1,432 @@
+package poly
+
+import (
+	"context"
+	"math/big"
+	"sort"
+)
+
+// A polynomial with integer coefficients, stored from the constant term upwards.
+type intPoly []*big.Int
+
+// A factor of an integer polynomial along with how many times it divides it.
+type intFactor struct {
+	f            intPoly
+	multiplicity int
+}
+
+// Convert the polynomial into a primitive integer polynomial with a positive leading coefficient. The result has the
+// same roots, and differs from the polynomial only by a constant factor.
+func (p Polynomial) primitive() intPoly {
+	return intPoly(p.scaleToIntegers()).primitive()
+}
+
+// Convert the integer polynomial back into a Polynomial.
+func (f intPoly) toRat() Polynomial {
+	r := make(Polynomial, len(f))
+	for i, v := range f {
+		r[i] = new(big.Rat).SetInt(v)
+	}
+
+	return r.trim()
+}
+
+func (f intPoly) trim() intPoly {
+	for len(f) > 0 && f[len(f)-1].Sign() == 0 {
+		f = f[:len(f)-1]
+	}
+
+	return f
+}
+
+func (f intPoly) degree() int {
+	return len(f.trim()) - 1
+}
+
+func (f intPoly) leading() *big.Int {
+	return f[len(f)-1]
+}
+
+// Divide out the gcd of the coefficients, and make the leading coefficient positive.
+func (f intPoly) primitive() intPoly {
+	f = f.trim()
+
+	content := new(big.Int)
+	for _, v := range f {
+		content.GCD(nil, nil, content, new(big.Int).Abs(v))
+	}
+	if len(f) > 0 && f.leading().Sign() < 0 {
+		content.Neg(content)
+	}
+
+	r := make(intPoly, len(f))
+	for i, v := range f {
+		r[i] = new(big.Int).Quo(v, content)
+	}
+
+	return r
+}
+
+func intMul(f, g intPoly) intPoly {
+	if len(f) == 0 || len(g) == 0 {
+		return intPoly{}
+	}
+
+	r := make(intPoly, len(f)+len(g)-1)
+	for i := range r {
+		r[i] = new(big.Int)
+	}
+	for i, a := range f {
+		for j, b := range g {
+			r[i+j].Add(r[i+j], new(big.Int).Mul(a, b))
+		}
+	}
+
+	return r.trim()
+}
+
+// Reduce every coefficient of f modulo m, into the range [0, m).
+func intMod(f intPoly, m *big.Int) intPoly {
+	r := make(intPoly, len(f))
+	for i, v := range f {
+		r[i] = new(big.Int).Mod(v, m)
+	}
+
+	return r.trim()
+}
+
+// Reduce every coefficient of f modulo m, into the range (-m/2, m/2].
+func intModSymmetric(f intPoly, m *big.Int) intPoly {
+	half := new(big.Int).Rsh(m, 1)
+
+	r := intMod(f, m)
+	for _, v := range r {
+		if v.Cmp(half) > 0 {
+			v.Sub(v, m)
+		}
+	}
+
+	return r
+}
+
+// Divide f by the monic polynomial g modulo m, returning the quotient and the remainder.
+func intDivModMonic(f, g intPoly, m *big.Int) (intPoly, intPoly) {
+	r := intMod(f, m)
+	g = g.trim()
+	if len(r) < len(g) {
+		return intPoly{}, r
+	}
+
+	quotient := make(intPoly, len(r)-len(g)+1)
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c := new(big.Int).Set(r[i+len(g)-1])
+		quotient[i] = c
+		for j, v := range g {
+			r[i+j].Sub(r[i+j], new(big.Int).Mul(c, v)).Mod(r[i+j], m)
+		}
+	}
+
+	return quotient.trim(), r[:len(g)-1].trim()
+}
+
+// Divide f by g over the integers, returning false if g does not divide f exactly.
+func intDivExact(f, g intPoly) (intPoly, bool) {
+	f, g = f.trim(), g.trim()
+	if len(f) < len(g) {
+		return nil, len(f) == 0
+	}
+
+	r := make(intPoly, len(f))
+	for i, v := range f {
+		r[i] = new(big.Int).Set(v)
+	}
+
+	quotient := make(intPoly, len(f)-len(g)+1)
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c, m := new(big.Int).QuoRem(r[i+len(g)-1], g.leading(), new(big.Int))
+		if m.Sign() != 0 {
+			return nil, false
+		}
+		quotient[i] = c
+		for j, v := range g {
+			r[i+j].Sub(r[i+j], new(big.Int).Mul(c, v))
+		}
+	}
+
+	for _, v := range r[:len(g)-1] {
+		if v.Sign() != 0 {
+			return nil, false
+		}
+	}
+
+	return quotient.trim(), true
+}
+
+// Factor a polynomial into irreducible factors over the integers. Every factor is primitive with a positive leading
+// coefficient, and the constant factor is not included. The factors are incomplete if the context is done first.
+func factorIntegers(ctx context.Context, p Polynomial) []intFactor {
+	var r []intFactor
+	for _, v := range squareFree(ctx, p) {
+		for _, f := range zassenhaus(ctx, v.f) {
+			r = append(r, intFactor{f, v.multiplicity})
+		}
+	}
+
+	return r
+}
+
+// Split a polynomial into square-free factors, each of which is coprime with the others, using Yun's algorithm. Returns
+// nil if the context is done.
+func squareFree(ctx context.Context, p Polynomial) []intFactor {
+	a := gcd(ctx, p, p.Derivative())
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	var (
+		r    []intFactor
+		b, _ = p.DivMod(a)
+		c, _ = p.Derivative().DivMod(a)
+		d    = c.Sub(b.Derivative())
+	)
+	for i := 1; b.Degree() > 0; i++ {
+		if a = gcd(ctx, b, d); ctx.Err() != nil {
+			return nil
+		}
+		b, _ = b.DivMod(a)
+		c, _ = d.DivMod(a)
+		d = c.Sub(b.Derivative())
+
+		if a.Degree() > 0 {
+			r = append(r, intFactor{a.primitive(), i})
+		}
+	}
+
+	return r
+}
+
+// Factor a primitive square-free integer polynomial into irreducible factors using the Zassenhaus algorithm: it is
+// factored modulo a prime, the factors are lifted to a large enough power of that prime with Hensel lifting, and
+// finally combined to find the true factors. Returns nil if the context is done.
+func zassenhaus(ctx context.Context, f intPoly) []intPoly {
+	if f.degree() <= 1 {
+		return []intPoly{f}
+	}
+
+	p, modular := choosePrime(ctx, f)
+	if ctx.Err() != nil {
+		return nil
+	} else if len(modular) == 1 {
+		return []intPoly{f}
+	}
+
+	// Any factor has coefficients no larger than 2^n‖f‖ by Mignotte's bound, and multiplying by the leading coefficient
+	// and allowing for negative coefficients means the modulus has to be larger than 2·|lc|·2^n‖f‖
+	norm := new(big.Int)
+	for _, v := range f {
+		norm.Add(norm, new(big.Int).Mul(v, v))
+	}
+	bound := norm.Sqrt(norm).Add(norm, big.NewInt(1))
+	bound.Mul(bound, new(big.Int).Abs(f.leading())).Lsh(bound, uint(f.degree()+1))
+
+	m := big.NewInt(p)
+	for m.Cmp(bound) <= 0 {
+		m.Mul(m, m)
+	}
+
+	lifted := henselLift(ctx, f, modular, p, m)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return recombine(ctx, f, lifted, m)
+}
+
+// Choose a prime that doesn't divide the leading coefficient of f and that keeps f square-free, returning it along
+// with the factors of f modulo that prime. Several primes are tried and the one giving the fewest factors is chosen,
+// because every extra factor doubles the work done when recombining. Returns no factors if the context is done.
+func choosePrime(ctx context.Context, f intPoly) (int64, []gfPoly) {
+	var (
+		best    int64
+		factors []gfPoly
+		tried   int
+	)
+	for p := int64(3); tried < 5; p += 2 {
+		if ctx.Err() != nil {
+			return 0, nil
+		} else if !big.NewInt(p).ProbablyPrime(0) {
+			continue
+		}
+
+		g := gfFromInt(f, p)
+		if g.degree() != f.degree() || gfGCD(g, gfDerivative(g, p), p).degree() > 0 {
+			continue
+		}
+		tried++
+
+		if r := gfFactor(ctx, gfMonic(g, p), p); factors == nil || len(r) < len(factors) {
+			best, factors = p, r
+		}
+		if len(factors) == 1 {
+			break
+		}
+	}
+
+	return best, factors
+}
+
+// Lift the factorization f ≡ lc(f)·g₁·g₂···gₖ (mod p), where each gᵢ is monic, to a factorization modulo m, which must
+// be p raised to a power of 2. The factors are split into two groups that are lifted together and then recursively
+// split further. Returns nil if the context is done.
+func henselLift(ctx context.Context, f intPoly, factors []gfPoly, p int64, m *big.Int) []intPoly {
+	if len(factors) == 1 {
+		inv := new(big.Int).ModInverse(f.leading(), m)
+		return []intPoly{intMod(intMul(f, intPoly{inv}), m)}
+	}
+
+	// g takes the leading coefficient so that h can stay monic
+	left, right := factors[:len(factors)/2], factors[len(factors)/2:]
+	g0 := gfPoly{new(big.Int).Mod(f.leading(), big.NewInt(p)).Int64()}
+	for _, v := range left {
+		g0 = gfMul(g0, v, p)
+	}
+	h0 := gfPoly{1}
+	for _, v := range right {
+		h0 = gfMul(h0, v, p)
+	}
+	_, s0, t0 := gfExtendedGCD(g0, h0, p)
+
+	g, h, s, t := g0.toInt(), h0.toInt(), s0.toInt(), t0.toInt()
+	for q := big.NewInt(p); q.Cmp(m) < 0; {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		q.Mul(q, q)
+		g, h, s, t = henselStep(f, g, h, s, t, q)
+	}
+
+	return append(henselLift(ctx, g, left, p, m), henselLift(ctx, h, right, p, m)...)
+}
+
+// Lift f ≡ gh (mod √q) and sg + th ≡ 1 (mod √q) to the same equations modulo q. h must be monic.
+func henselStep(f, g, h, s, t intPoly, q *big.Int) (intPoly, intPoly, intPoly, intPoly) {
+	one := intPoly{big.NewInt(1)}
+
+	e := intMod(subInt(f, intMul(g, h)), q)
+	quotient, remainder := intDivModMonic(intMul(s, e), h, q)
+	g = intMod(addInt(g, addInt(intMul(t, e), intMul(quotient, g))), q)
+	h = intMod(addInt(h, remainder), q)
+
+	b := intMod(subInt(addInt(intMul(s, g), intMul(t, h)), one), q)
+	c, d := intDivModMonic(intMul(s, b), h, q)
+	s = intMod(subInt(s, d), q)
+	t = intMod(subInt(t, addInt(intMul(t, b), intMul(c, g))), q)
+
+	return g, h, s, t
+}
+
+// Find the true factors of f from its monic factors modulo m, by trying each combination of modular factors, starting
+// with the smallest combinations. There can be exponentially many combinations, so they are tried one at a time and the
+// search stops early if the context is done.
+func recombine(ctx context.Context, f intPoly, lifted []intPoly, m *big.Int) []intPoly {
+	var (
+		r         []intPoly
+		remaining = make([]int, len(lifted))
+	)
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	for size := 1; 2*size <= len(remaining) && ctx.Err() == nil; size++ {
+		subset := make([]int, size)
+		for i := range subset {
+			subset[i] = i
+		}
+		for more := true; more && ctx.Err() == nil; more = nextCombination(subset, len(remaining)) {
+			// The leading coefficient is multiplied in, since lc(f) is a multiple of the leading coefficient of any factor
+			g := intPoly{f.leading()}
+			for _, i := range subset {
+				g = intMul(g, lifted[remaining[i]])
+			}
+			g = intModSymmetric(g, m).primitive()
+
+			quotient, ok := intDivExact(f, g)
+			if !ok {
+				continue
+			}
+
+			// A factor was found, so remove the modular factors that made it up and keep searching with the same size
+			r = append(r, g)
+			f = quotient
+			var kept []int
+			for i, v := range remaining {
+				if !containsIndex(subset, i) {
+					kept = append(kept, v)
+				}
+			}
+			remaining = kept
+			size--
+			break
+		}
+	}
+
+	r = append(r, f.primitive())
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].degree() < r[j].degree()
+	})
+	return r
+}
+
+// Advance a way of choosing some of the numbers 0 to n-1, given in increasing order, to the next one in lexicographic
+// order. Returns false if it was the last one.
+//  nextCombination([0 2 3], 4) -> [1 2 3], true
+func nextCombination(c []int, n int) bool {
+	k := len(c)
+	for i := k - 1; i >= 0; i-- {
+		if c[i] < n-k+i {
+			c[i]++
+			for j := i + 1; j < k; j++ {
+				c[j] = c[j-1] + 1
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsIndex(list []int, x int) bool {
+	for _, v := range list {
+		if v == x {
+			return true
+		}
+	}
+
+	return false
+}
+
+func addInt(f, g intPoly) intPoly {
+	if len(g) > len(f) {
+		f, g = g, f
+	}
+
+	r := make(intPoly, len(f))
+	for i, v := range f {
+		r[i] = new(big.Int).Set(v)
+		if i < len(g) {
+			r[i].Add(r[i], g[i])
+		}
+	}
+
+	return r.trim()
+}
+
+func subInt(f, g intPoly) intPoly {
+	n := make(intPoly, len(g))
+	for i, v := range g {
+		n[i] = new(big.Int).Neg(v)
+	}
+
+	return addInt(f, n)
+}
@@ -0,0 +1,83 @@
+package poly_test
+
+import (
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+var _ = Describe("factoring over the integers", func() {
+	// Multiply the factors back together, ignoring constant factors
+	product := func(f poly.Factorization) poly.Polynomial {
+		r := poly.Ints(1)
+		for _, v := range f.Factors {
+			r = r.Mul(v.Polynomial.Pow(uint(v.Multiplicity)))
+		}
+		return r.Monic()
+	}
+
+	// Describe each factor by its degree and multiplicity so that factorizations can be compared regardless of order
+	shape := func(f poly.Factorization) []string {
+		var r []string
+		for _, v := range f.Factors {
+			r = append(r, v.Polynomial.Monic().String()+"^"+strconv.Itoa(v.Multiplicity))
+		}
+		sort.Strings(r)
+		return r
+	}
+
+	DescribeTable("polynomials without rational roots",
+		func(p poly.Polynomial, result poly.Result, factors []poly.Polynomial) {
+			f, e := poly.Factor(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(f.Result).To(Equal(result))
+			Expect(product(f).Equal(p.Monic())).To(BeTrue())
+
+			expected := poly.Factorization{}
+			for _, v := range factors {
+				expected.Factors = append(expected.Factors, poly.Component{Polynomial: v, Multiplicity: 1})
+			}
+			Expect(shape(f)).To(Equal(shape(expected)))
+		},
+		Entry("x^4 + 4", poly.Ints(4, 0, 0, 0, 1), poly.Partial, []poly.Polynomial{poly.Ints(2, 2, 1), poly.Ints(2, -2, 1)}),
+		Entry("(x^2 + 1)(x^2 + 2)", poly.Ints(2, 0, 3, 0, 1), poly.Partial, []poly.Polynomial{poly.Ints(1, 0, 1), poly.Ints(2, 0, 1)}),
+		Entry("(x^2 - 2)(x^2 - 3)", poly.Ints(6, 0, -5, 0, 1), poly.Quadratic, []poly.Polynomial{poly.Ints(-2, 0, 1), poly.Ints(-3, 0, 1)}),
+		Entry("(2x^3 + x + 1)(3x^3 - x^2 + 5)", poly.Ints(1, 1, 0, 2).Mul(poly.Ints(5, 0, -1, 3)), poly.Partial, []poly.Polynomial{poly.Ints(1, 1, 0, 2), poly.Ints(5, 0, -1, 3)}),
+		Entry("x^4 - 10x^2 + 1, which factors modulo every prime", poly.Ints(1, 0, -10, 0, 1), poly.Not, []poly.Polynomial{poly.Ints(1, 0, -10, 0, 1)}),
+		Entry("the irreducible x^5 - x - 1", poly.Ints(-1, -1, 0, 0, 0, 1), poly.Not, []poly.Polynomial{poly.Ints(-1, -1, 0, 0, 0, 1)}),
+	)
+
+	It("should find repeated irreducible factors", func() {
+		f, e := poly.Factor(poly.Ints(1, 0, 1).Pow(2).Mul(poly.Ints(3, 0, 1)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(f.Result).To(Equal(poly.Partial))
+		Expect(shape(f)).To(Equal([]string{"x^2 + 1^2", "x^2 + 3^1"}))
+	})
+
+	It("should factor randomly generated products of polynomials", func() {
+		r := rand.New(rand.NewSource(1617000000))
+		for n := 0; n < 20; n++ {
+			// Build a product of 2 to 4 factors of degree 2 to 4 with no zero constant terms
+			p := poly.Ints(1)
+			for i := r.Intn(3) + 2; i > 0; i-- {
+				coefficients := make([]int64, r.Intn(3)+3)
+				for j := range coefficients {
+					coefficients[j] = int64(r.Intn(19) - 9)
+				}
+				coefficients[0] = int64(r.Intn(9) + 1)
+				coefficients[len(coefficients)-1] = int64(r.Intn(3) + 1)
+				p = p.Mul(poly.Ints(coefficients...))
+			}
+
+			By("factoring " + p.String())
+			f, e := poly.Factor(p)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(product(f).Equal(p.Monic())).To(BeTrue())
+			Expect(len(f.Factors)).To(BeNumerically(">=", 2))
+		}
+	})
+})
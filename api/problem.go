@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// The prefix of the URI that identifies each kind of problem, which is followed by its code.
+const problemType = "urn:quick-factor:problem:"
+
+// A stable, machine-readable code for a kind of problem with a request.
+type ProblemCode string
+
+const (
+	InvalidBody            ProblemCode = "invalid_body"             // The request body couldn't be read or parsed
+	UnknownField           ProblemCode = "unknown_field"            // A JSON request body has a field that isn't in its schema
+	InvalidType            ProblemCode = "invalid_type"             // A JSON field has the wrong type
+	MissingDegree          ProblemCode = "missing_degree"           // The 'degree' parameter wasn't given
+	InvalidDegree          ProblemCode = "invalid_degree"           // The 'degree' parameter isn't an integer
+	DegreeTooLow           ProblemCode = "degree_too_low"           // The polynomial has a degree less than 2
+	DegreeTooHigh          ProblemCode = "degree_too_high"          // The polynomial has a degree larger than poly.MaxParseDegree
+	InvalidCoefficient     ProblemCode = "invalid_coefficient"      // A coefficient isn't a number
+	ZeroLeadingCoefficient ProblemCode = "zero_leading_coefficient" // The coefficient of the highest power of x is 0
+	InvalidExpression      ProblemCode = "invalid_expression"       // An expression couldn't be parsed
+	MissingPolynomial      ProblemCode = "missing_polynomial"       // Neither an expression nor coefficients were given
+	ConflictingPolynomial  ProblemCode = "conflicting_polynomial"   // Both an expression and coefficients were given
+	InvalidBoolean         ProblemCode = "invalid_boolean"          // A parameter that has to be true or false is neither
+	InvalidMode            ProblemCode = "invalid_mode"             // The solver mode isn't one that exists
+	InvalidFormat          ProblemCode = "invalid_format"           // The output format isn't one that exists
+	InvalidDomain          ProblemCode = "invalid_domain"           // The domain isn't one that exists
+	InvalidPrecision       ProblemCode = "invalid_precision"        // The precision isn't an integer in range
+	UnexpectedPrecision    ProblemCode = "unexpected_precision"     // A precision was given outside of numeric mode
+	InvalidWidth           ProblemCode = "invalid_width"            // The width of root intervals isn't a positive number, or is too small
+	EmptyBatch             ProblemCode = "empty_batch"              // A batch has no items
+	TooManyItems           ProblemCode = "too_many_items"           // A batch has more items than are allowed
+	MethodNotAllowed       ProblemCode = "method_not_allowed"       // The request used a method that isn't allowed
+	UnsupportedMediaType   ProblemCode = "unsupported_media_type"   // The request body has a media type that isn't accepted
+	BodyTooLarge           ProblemCode = "body_too_large"           // The request body is larger than is allowed
+	PreflightRejected      ProblemCode = "preflight_rejected"       // A CORS preflight request asked for something that isn't allowed
+	NotConverged           ProblemCode = "not_converged"            // The roots couldn't be approximated numerically
+	RootTooLarge           ProblemCode = "root_too_large"           // A root is too large to be written as a JSON number
+	Timeout                ProblemCode = "timeout"                  // Factoring took longer than the server allows
+	InternalError          ProblemCode = "internal_error"           // Something went wrong that isn't a problem with the request
+)
+
+// The status code and title of each kind of problem. Requests that can't be parsed are bad requests, and requests that
+// can be parsed but don't make sense are unprocessable.
+var problems = map[ProblemCode]struct {
+	status int
+	title  string
+}{
+	InvalidBody:            {http.StatusBadRequest, "Invalid request body"},
+	UnknownField:           {http.StatusBadRequest, "Unknown field"},
+	InvalidType:            {http.StatusBadRequest, "Field has the wrong type"},
+	MissingDegree:          {http.StatusBadRequest, "Missing degree"},
+	InvalidDegree:          {http.StatusBadRequest, "Invalid degree"},
+	DegreeTooLow:           {http.StatusUnprocessableEntity, "Degree too low"},
+	DegreeTooHigh:          {http.StatusUnprocessableEntity, "Degree too high"},
+	InvalidCoefficient:     {http.StatusBadRequest, "Invalid coefficient"},
+	ZeroLeadingCoefficient: {http.StatusUnprocessableEntity, "Leading coefficient is zero"},
+	InvalidExpression:      {http.StatusUnprocessableEntity, "Invalid expression"},
+	MissingPolynomial:      {http.StatusBadRequest, "Missing polynomial"},
+	ConflictingPolynomial:  {http.StatusBadRequest, "Conflicting polynomials"},
+	InvalidBoolean:         {http.StatusBadRequest, "Invalid boolean"},
+	InvalidMode:            {http.StatusUnprocessableEntity, "Invalid mode"},
+	InvalidFormat:          {http.StatusUnprocessableEntity, "Invalid format"},
+	InvalidDomain:          {http.StatusUnprocessableEntity, "Invalid domain"},
+	InvalidPrecision:       {http.StatusUnprocessableEntity, "Invalid precision"},
+	UnexpectedPrecision:    {http.StatusUnprocessableEntity, "Precision outside of numeric mode"},
+	InvalidWidth:           {http.StatusUnprocessableEntity, "Invalid width"},
+	EmptyBatch:             {http.StatusUnprocessableEntity, "Empty batch"},
+	TooManyItems:           {http.StatusUnprocessableEntity, "Too many items"},
+	MethodNotAllowed:       {http.StatusMethodNotAllowed, "Method not allowed"},
+	UnsupportedMediaType:   {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	BodyTooLarge:           {http.StatusRequestEntityTooLarge, "Request body too large"},
+	PreflightRejected:      {http.StatusForbidden, "CORS preflight rejected"},
+	NotConverged:           {http.StatusUnprocessableEntity, "Roots not found"},
+	RootTooLarge:           {http.StatusUnprocessableEntity, "Root too large"},
+	Timeout:                {http.StatusServiceUnavailable, "Timed out"},
+	InternalError:          {http.StatusInternalServerError, "Internal error"},
+}
+
+// Struct defining an RFC 7807 problem details object, which describes why a request failed.
+type ProblemJSON struct {
+	Type   string      `json:"type"`   // A URI made from the code, like "urn:quick-factor:problem:missing_degree"
+	Title  string      `json:"title"`  // A summary of the kind of problem, which is the same for every problem with the code
+	Status int         `json:"status"` // The HTTP status code
+	Detail string      `json:"detail"` // An explanation of this particular problem
+	Code   ProblemCode `json:"code"`
+	Param  string      `json:"param,omitempty"`  // The parameter or field that caused the problem, like "x^2" or "coefficients[2]"
+	Column int         `json:"column,omitempty"` // For an invalid expression, the column where it stopped making sense
+}
+
+// Create a problem with the code, caused by the parameter 'param' if it isn't empty. The detail is formatted like
+// fmt.Sprintf.
+func newProblem(code ProblemCode, param string, detail string, a ...interface{}) *ProblemJSON {
+	p := problems[code]
+	return &ProblemJSON{
+		Type:   problemType + string(code),
+		Title:  p.title,
+		Status: p.status,
+		Detail: fmt.Sprintf(detail, a...),
+		Code:   code,
+		Param:  param,
+	}
+}
+
+// Write a problem as the response, with its status code.
+func writeProblem(w http.ResponseWriter, p *ProblemJSON) {
+	b, _ := json.MarshalIndent(p, "", "  ") // A ProblemJSON can always be marshalled
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(b)
+}
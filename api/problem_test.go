@@ -0,0 +1,47 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/noahfriedman-ca/quick-factor/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Read the problem that a response was written with, checking that it was written as problem details with a status that
+// matches the response.
+func readProblem(w *httptest.ResponseRecorder) api.ProblemJSON {
+	Expect(w.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+
+	var p api.ProblemJSON
+	Expect(json.Unmarshal(w.Body.Bytes(), &p)).To(Succeed())
+	Expect(p.Status).To(Equal(w.Code))
+	return p
+}
+
+var _ = Describe("problem details", func() {
+	It("should be written with every member of RFC 7807", func() {
+		w := httptest.NewRecorder()
+		api.Factor(w, httptest.NewRequest("", "https://example.com?degree=3&x^3=0", nil))
+
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(w.Body.String()).To(MatchJSON(`{
+			"type": "urn:quick-factor:problem:zero_leading_coefficient",
+			"title": "Leading coefficient is zero",
+			"status": 422,
+			"detail": "Query parameter 'x^3' must not be 0",
+			"code": "zero_leading_coefficient",
+			"param": "x^3"
+		}`))
+	})
+	It("should give the column of an expression that can't be parsed", func() {
+		w := httptest.NewRecorder()
+		api.Factor(w, httptest.NewRequest("", "https://example.com?expr=x^2%2By", nil))
+
+		p := readProblem(w)
+		Expect(p.Code).To(Equal(api.InvalidExpression))
+		Expect(p.Param).To(Equal("expr"))
+		Expect(p.Column).To(Equal(5))
+	})
+})
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/noahfriedman-ca/quick-factor/api/poly"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	Register(Endpoint{
+		Name:   "roots",
+		Method: http.MethodGet,
+		Params: append(polynomialParams(),
+			Param{Name: "width", In: "query", Type: "string", Example: "1/1000", Description: fmt.Sprintf("The widest that an interval around a root can be, as a positive integer, decimal or fraction no smaller than 1e-%d", poly.MaxDigits)},
+		),
+		Description: "Count the real roots of a polynomial and isolate each of them in an interval with rational endpoints.",
+		Handler:     Roots,
+		Response:    RootsJSON{},
+	})
+}
+
+// The narrowest that intervals can be refined to, which is as precise as roots can be approximated in numeric mode.
+var minWidth = new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(poly.MaxDigits), nil))
+
+// Struct defining the JSON response from the Roots API function.
+type RootsJSON struct {
+	Count     int            `json:"count"` // The number of distinct real roots, found using Sturm's theorem
+	Descartes DescartesJSON  `json:"descartes"`
+	Intervals []IntervalJSON `json:"intervals"` // One interval for each distinct real root, in ascending order
+}
+
+// Struct defining the JSON representation of the bounds given by Descartes' rule of signs. The true number of roots,
+// counted with multiplicity, is either the bound or less than it by an even number.
+type DescartesJSON struct {
+	Positive int `json:"positive"`
+	Negative int `json:"negative"`
+}
+
+// Struct defining the JSON representation of an interval that contains exactly one real root. The root lies strictly
+// between Lower and Upper, unless Exact is true, in which case Lower and Upper are the same and equal to the root.
+type IntervalJSON struct {
+	Lower        RationalJSON `json:"lower"`
+	Upper        RationalJSON `json:"upper"`
+	LowerDecimal string       `json:"lowerDecimal"`
+	UpperDecimal string       `json:"upperDecimal"`
+	Exact        bool         `json:"exact,omitempty"`
+}
+
+// API function for locating the real roots of polynomials, without factoring them. The polynomial is given in the same
+// way as to Factor. The response contains the number of real roots, the bounds on the number of positive and negative
+// roots from Descartes' rule of signs, and an interval with rational endpoints around each root. If the 'width'
+// parameter is given, every interval is narrowed until it is no wider than that, which can't be narrower than 1e-100.
+func Roots(w http.ResponseWriter, r *http.Request) {
+	if e := r.ParseForm(); e != nil {
+		writeProblem(w, newProblem(InvalidBody, "", "Could not parse request body"))
+		return
+	}
+
+	var (
+		coefficients poly.Polynomial
+		problem      *ProblemJSON
+	)
+	if s := strings.Trim(r.Form.Get("expr"), " "); s != "" { // Extra whitespace is trimmed to avoid unintentional errors
+		coefficients, problem = parseExpression(s, "Parameter", "expr")
+	} else {
+		coefficients, problem = parseCoefficients(r.Form)
+	}
+	if problem != nil {
+		writeProblem(w, problem)
+		return
+	}
+
+	// Intervals are only refined if a width is requested
+	var width *big.Rat
+	if s := strings.Trim(r.Form.Get("width"), " "); s != "" {
+		if v, ok := new(big.Rat).SetString(s); !ok || v.Sign() <= 0 {
+			writeProblem(w, newProblem(InvalidWidth, "width", "Parameter 'width' must be a positive number"))
+			return
+		} else if v.Cmp(minWidth) < 0 {
+			writeProblem(w, newProblem(InvalidWidth, "width", "Parameter 'width' must not be smaller than 1e-%d", poly.MaxDigits))
+			return
+		} else {
+			width = v
+		}
+	}
+
+	intervals, e := coefficients.IsolateRootsContext(r.Context())
+	if e != nil {
+		writeRootsError(w, e)
+		return
+	}
+
+	count, e := coefficients.CountRealRootsContext(r.Context())
+	if e != nil {
+		writeRootsError(w, e)
+		return
+	}
+
+	result := RootsJSON{Count: count, Intervals: []IntervalJSON{}}
+	result.Descartes.Positive, result.Descartes.Negative = coefficients.DescartesBounds()
+	for _, v := range intervals {
+		if width != nil {
+			if v, e = coefficients.RefineIntervalContext(r.Context(), v, width); e != nil {
+				writeRootsError(w, e)
+				return
+			}
+		}
+
+		result.Intervals = append(result.Intervals, IntervalJSON{
+			Lower:        newRationalJSON(v.Lower),
+			Upper:        newRationalJSON(v.Upper),
+			LowerDecimal: formatDecimal(v.Lower),
+			UpperDecimal: formatDecimal(v.Upper),
+			Exact:        v.IsExact(),
+		})
+	}
+
+	// Write the result
+	if b, e := json.MarshalIndent(result, "", "  "); e != nil {
+		writeProblem(w, newProblem(InternalError, "", "Failed to find roots"))
+		log.Println(e)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	}
+}
+
+// Write the problem for an error from locating roots. Nothing is written if the client went away.
+func writeRootsError(w http.ResponseWriter, e error) {
+	switch e {
+	case context.DeadlineExceeded:
+		writeProblem(w, newProblem(Timeout, "", "Finding roots took longer than the server allows"))
+	case context.Canceled:
+	default:
+		writeProblem(w, newProblem(InternalError, "", "Failed to find roots"))
+		log.Println(e)
+	}
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/noahfriedman-ca/quick-factor/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+)
+
+var _ = Describe("the Roots function", func() {
+	getResponse := func(queries string) string {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("", "https://example.com?"+queries, nil)
+
+		api.Roots(w, r)
+
+		b, e := ioutil.ReadAll(w.Result().Body)
+		Expect(e).NotTo(HaveOccurred())
+
+		return string(b)
+	}
+
+	getRoots := func(queries string) api.RootsJSON {
+		resp := getResponse(queries)
+		Expect(resp).NotTo(ContainSubstring("urn:quick-factor:problem:"))
+
+		var actual api.RootsJSON
+		Expect(json.Unmarshal([]byte(resp), &actual)).To(Succeed())
+		return actual
+	}
+
+	ratOf := func(r api.RationalJSON) *big.Rat {
+		v, ok := new(big.Rat).SetString(string(r.Numerator) + "/" + string(r.Denominator))
+		Expect(ok).To(BeTrue())
+		return v
+	}
+
+	It("should count the roots and give the bounds from Descartes' rule of signs", func() {
+		actual := getRoots("expr=" + url.QueryEscape("x^3 - 3x + 1"))
+		Expect(actual.Count).To(Equal(3))
+		Expect(actual.Descartes).To(Equal(api.DescartesJSON{Positive: 2, Negative: 1}))
+		Expect(actual.Intervals).To(HaveLen(3))
+	})
+
+	It("should give an empty list of intervals when there are no real roots", func() {
+		actual := getRoots("degree=2&x^0=1&x^2=1")
+		Expect(actual.Count).To(Equal(0))
+		Expect(actual.Intervals).To(BeEmpty())
+	})
+
+	It("should refine the intervals to the requested width", func() {
+		actual := getRoots("expr=" + url.QueryEscape("x^2 - 2") + "&width=" + url.QueryEscape("1/1000"))
+		Expect(actual.Intervals).To(HaveLen(2))
+
+		for i, v := range actual.Intervals {
+			lower, upper := ratOf(v.Lower), ratOf(v.Upper)
+			Expect(new(big.Rat).Sub(upper, lower).Cmp(big.NewRat(1, 1000))).To(BeNumerically("<=", 0))
+			Expect(lower.FloatString(2)).To(Equal([]string{"-1.41", "1.41"}[i]))
+		}
+	})
+
+	It("should mark an interval as exact when it finds a rational root", func() {
+		actual := getRoots("expr=" + url.QueryEscape("(x - 1)(x + 1)(x - 3)") + "&width=0.001")
+		Expect(actual.Intervals[2]).To(Equal(api.IntervalJSON{
+			Lower:        api.RationalJSON{Numerator: "3", Denominator: "1"},
+			Upper:        api.RationalJSON{Numerator: "3", Denominator: "1"},
+			LowerDecimal: "3",
+			UpperDecimal: "3",
+			Exact:        true,
+		}))
+	})
+
+	DescribeTable("when an error should be thrown",
+		func(queries string, code api.ProblemCode) {
+			w := httptest.NewRecorder()
+			api.Roots(w, httptest.NewRequest("", "https://example.com?"+queries, nil))
+			Expect(readProblem(w).Code).To(Equal(code))
+		},
+		Entry("when the polynomial is missing", "", api.MissingDegree),
+		Entry("when the width isn't a number", "expr=x%5E2-2&width=narrow", api.InvalidWidth),
+		Entry("when the width isn't positive", "expr=x%5E2-2&width=0", api.InvalidWidth),
+		Entry("when the width is too small", "expr=x%5E2-2&width=1e-200000", api.InvalidWidth),
+	)
+
+	It("should time out once the deadline has passed", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		w := httptest.NewRecorder()
+		api.Roots(w, httptest.NewRequest("", "https://example.com?expr=x%5E2-2&width=1e-100", nil).WithContext(ctx))
+
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(readProblem(w).Code).To(Equal(api.Timeout))
+	})
+})
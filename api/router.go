@@ -1,42 +1,103 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gorilla/mux"
 	"net/http"
-	"reflect"
-	"regexp"
-	"runtime"
-	"strings"
+	"sort"
 )
 
-var funcs []http.HandlerFunc
+// Struct defining a parameter of an endpoint, as it is described in the catalogue of endpoints.
+type Param struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`   // Where the parameter is given; either "query", for a query or form parameter, or "body"
+	Type        string      `json:"type"` // A JSON type; either "string", "integer", "number", "boolean", "array" or "object"
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Enum        []string    `json:"enum,omitempty"` // The only values that the parameter can have, if there are only a few
+	Example     interface{} `json:"example,omitempty"`
+	Description string      `json:"description"`
+}
+
+// Struct defining an endpoint of the API, which is routed at its name and described in the catalogue of endpoints.
+type Endpoint struct {
+	Name        string           `json:"name"`   // The path of the endpoint below the root of the API, like "factor" or "v1/batch"
+	Method      string           `json:"method"` // The HTTP method that the endpoint is meant to be called with
+	Params      []Param          `json:"params,omitempty"`
+	MediaTypes  []string         `json:"mediaTypes,omitempty"` // The media types that the request body can have, which the response is written in as well
+	Description string           `json:"description"`
+	Handler     http.HandlerFunc `json:"-"`
+	Request     interface{}      `json:"-"` // A value of the type of the request body, if the endpoint has one
+	Response    interface{}      `json:"-"` // A value of the type of a successful response
+}
+
+var endpoints = map[string]Endpoint{}
+
+// Register an endpoint so that it is routed by every router created afterwards. Like http.HandleFunc, it panics if the
+// endpoint has no name or handler, or if an endpoint with the same name is already registered.
+func Register(e Endpoint) {
+	switch {
+	case e.Name == "":
+		panic("api: endpoint must have a name")
+	case e.Handler == nil:
+		panic(fmt.Sprintf("api: endpoint '%s' must have a handler", e.Name))
+	}
+	if _, ok := endpoints[e.Name]; ok {
+		panic(fmt.Sprintf("api: endpoint '%s' is already registered", e.Name))
+	}
+
+	endpoints[e.Name] = e
+}
 
-// Create a router configured properly for this program.
+// Create a router configured properly for this program, with the default configuration.
 func Router() *mux.Router {
+	return NewRouter(DefaultConfig())
+}
+
+// Create a router that routes the API under the prefix in the configuration, follows its CORS policy and gives up on
+// factoring after its factor timeout. Anything else under the prefix is answered with the catalogue of endpoints.
+func NewRouter(c Config) *mux.Router {
 	rtr := mux.NewRouter()
-	r := rtr.PathPrefix("/projects/quick-factor/api").Subrouter()
+	rtr.Use(c.CORS.handler, c.Timeouts.handler)
 
-	var funcsJSON = struct {
-		Available []string `json:"available"`
-	}{}
+	r := rtr
+	if c.Prefix != "" {
+		r = rtr.PathPrefix(c.Prefix).Subrouter()
+	}
 
-	for _, v := range funcs {
-		// Extract function names
-		t := string(regexp.MustCompile(`\.([^.]*)$`).FindSubmatch([]byte(runtime.FuncForPC(reflect.ValueOf(v).Pointer()).Name()))[1])
+	var funcsJSON = struct {
+		Available []Endpoint `json:"available"`
+	}{Available: []Endpoint{}}
 
-		// Convert the first character to lowercase
-		t = strings.ToLower(t[0:1]) + t[1:]
+	// Map endpoints in a fixed order, so that the catalogue doesn't change
+	var names []string
+	for k := range endpoints {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		v := endpoints[k]
+		funcsJSON.Available = append(funcsJSON.Available, v)
+		r.Path("/" + k).HandlerFunc(v.Handler)
+	}
 
-		// Map all functions
-		funcsJSON.Available = append(funcsJSON.Available, t)
-		r.Path("/" + t).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			v(w, r)
-		})
+	// The OpenAPI document describes the same endpoints as the catalogue
+	server := c.Prefix
+	if server == "" {
+		server = "/"
+	}
+	openAPI, e := json.MarshalIndent(newOpenAPIJSON(server, funcsJSON.Available), "", "  ")
+	if e != nil {
+		panic(e)
 	}
+	r.Path("/openapi.json").HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
 
-	rtr.PathPrefix("/projects/quick-factor/api").HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	rtr.PathPrefix(c.Prefix).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		if b, e := json.MarshalIndent(funcsJSON, "", "    "); e != nil {
 			http.Error(w, e.Error(), http.StatusInternalServerError)
 		} else {
@@ -47,3 +108,17 @@ func Router() *mux.Router {
 
 	return rtr
 }
+
+// Give every request a deadline of the factor timeout, so that the factoring it started is abandoned when the deadline
+// passes, as well as when the client goes away.
+func (c TimeoutsConfig) handler(next http.Handler) http.Handler {
+	if c.Factor <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), c.Factor)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -8,19 +8,23 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 )
 
-func FuncOne(_ http.ResponseWriter, _ *http.Request)    {}
-func funcTwo(_ http.ResponseWriter, _ *http.Request)    {}
-func func_three(_ http.ResponseWriter, _ *http.Request) {}
+func FuncOne(_ http.ResponseWriter, _ *http.Request) {}
 
 var _ = Describe("the router", func() {
 	BeforeSuite(func() {
-		funcs = []http.HandlerFunc{
-			FuncOne,
-			funcTwo,
-			func_three,
-		}
+		Register(Endpoint{Name: "funcOne", Method: http.MethodGet, Description: "A named function", Handler: FuncOne})
+		Register(Endpoint{
+			Name:        "closure",
+			Method:      http.MethodGet,
+			Params:      []Param{{Name: "n", In: "query", Type: "integer", Default: 1, Example: 5, Description: "A number"}},
+			Description: "An anonymous function",
+			Handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("closure"))
+			},
+		})
 	})
 
 	var ms *httptest.Server
@@ -39,8 +43,8 @@ var _ = Describe("the router", func() {
 		}
 	}
 
-	It("should route each function in the 'funcs' array", func() {
-		for _, v := range []string{"funcOne", "funcTwo", "func_three"} {
+	It("should route each registered endpoint at its name", func() {
+		for _, v := range []string{"funcOne", "closure"} {
 			r, e := getResponse(v)
 			Expect(e).NotTo(HaveOccurred())
 
@@ -48,25 +52,31 @@ var _ = Describe("the router", func() {
 			Expect(s).NotTo(ContainSubstring("404 page not found"))
 			Expect(s).NotTo(ContainSubstring("\"available\":")) // This is done to check if the "help" text is displayed
 		}
+
+		r, e := getResponse("closure")
+		Expect(e).NotTo(HaveOccurred())
+		Expect(string(r)).To(Equal("closure"))
 	})
-	It("should lowercase the first character of the function name", func() {
-		f1, e := getResponse("funcOne")
+	It("should route endpoints at exactly their names", func() {
+		r, e := getResponse("FuncOne")
 		Expect(e).NotTo(HaveOccurred())
-
-		F1, e := getResponse("FuncOne")
+		Expect(string(r)).To(ContainSubstring("\"available\":"))
+	})
+	It("should reject an endpoint that is registered twice", func() {
+		Expect(func() { Register(Endpoint{Name: "funcOne", Handler: FuncOne}) }).To(Panic())
+		Expect(func() { Register(Endpoint{Name: "nothing"}) }).To(Panic())
+		Expect(func() { Register(Endpoint{Handler: FuncOne}) }).To(Panic())
+	})
+	It("should route versioned functions at their paths", func() {
+		r, e := http.Post(ms.URL+"/projects/quick-factor/api/v1/factor", "application/json", strings.NewReader(`{"coefficients": [-1, 0, 1]}`))
 		Expect(e).NotTo(HaveOccurred())
-
-		sf1 := string(f1)
-		sF1 := string(F1)
-		Expect(sf1).NotTo(ContainSubstring("404 page not found"))
-		Expect(sf1).NotTo(ContainSubstring("\"available\":"))
-		Expect(sF1).NotTo(ContainSubstring("404 page not found"))
-		Expect(sF1).To(ContainSubstring("\"available\":"))
+		Expect(r.StatusCode).To(Equal(http.StatusOK))
+		Expect(r.Header.Get("Access-Control-Allow-Origin")).To(Equal("*"))
 	})
-	DescribeTable("when the list of available functions should be displayed",
+	DescribeTable("when the catalogue of endpoints should be displayed",
 		func(path string) {
 			var resp struct {
-				Available []string `json:"available"`
+				Available []Endpoint `json:"available"`
 			}
 
 			r, e := getResponse(path)
@@ -75,9 +85,50 @@ var _ = Describe("the router", func() {
 
 			Expect(json.Unmarshal(r, &resp)).To(Succeed())
 
-			Expect(resp.Available).To(ContainElements("funcOne", "funcTwo", "func_three"))
+			var names []string
+			for _, v := range resp.Available {
+				names = append(names, v.Name)
+			}
+			Expect(names).To(Equal([]string{"closure", "factor", "funcOne", "roots", "v1/batch", "v1/factor"}))
+
+			Expect(resp.Available[0]).To(Equal(Endpoint{
+				Name:        "closure",
+				Method:      "GET",
+				Params:      []Param{{Name: "n", In: "query", Type: "integer", Default: 1.0, Example: 5.0, Description: "A number"}},
+				Description: "An anonymous function",
+			}))
 		},
 		Entry("the root path", ""),
 		Entry("any unrecognized path", "/test/path"),
 	)
+	It("should describe the parameters of each endpoint", func() {
+		r, e := getResponse("")
+		Expect(e).NotTo(HaveOccurred())
+
+		var resp struct {
+			Available []Endpoint `json:"available"`
+		}
+		Expect(json.Unmarshal(r, &resp)).To(Succeed())
+
+		for _, v := range resp.Available {
+			Expect(v.Method).NotTo(BeEmpty())
+			Expect(v.Description).NotTo(BeEmpty())
+			for _, p := range v.Params {
+				Expect(p.Type).To(BeElementOf("string", "integer", "number", "boolean", "array", "object"))
+				Expect(p.In).To(BeElementOf("query", "body"))
+				Expect(p.Description).NotTo(BeEmpty())
+			}
+		}
+
+		factor := resp.Available[1]
+		Expect(factor.Name).To(Equal("factor"))
+		Expect(factor.Params).To(ContainElement(Param{
+			Name:        "mode",
+			In:          "query",
+			Type:        "string",
+			Default:     "exact",
+			Enum:        []string{"exact", "radical", "numeric"},
+			Description: "How far to go when there are no more rational roots",
+		}))
+	})
 })
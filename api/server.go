@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Serve the handler with a server of its own, listening on the address in the configuration with its timeouts, and
+// over HTTPS if it has a certificate. When the context is done, the server stops accepting connections and waits for
+// the requests in progress to finish, for no longer than the shutdown timeout, before it returns.
+func Serve(ctx context.Context, c Config, h http.Handler) error {
+	l, e := net.Listen("tcp", c.Listen)
+	if e != nil {
+		return e
+	}
+
+	return serve(ctx, c, h, l)
+}
+
+// Serve the handler on a listener that is already open, which is closed when serving stops.
+func serve(ctx context.Context, c Config, h http.Handler, l net.Listener) error {
+	s := &http.Server{
+		Handler:           h,
+		ReadTimeout:       c.Timeouts.Read,
+		ReadHeaderTimeout: c.Timeouts.Read,
+		WriteTimeout:      c.Timeouts.Write,
+		IdleTimeout:       c.Timeouts.Idle,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if c.TLS.CertFile != "" {
+			errs <- s.ServeTLS(l, c.TLS.CertFile, c.TLS.KeyFile)
+		} else {
+			errs <- s.Serve(l)
+		}
+	}()
+
+	select {
+	case e := <-errs:
+		return e
+	case <-ctx.Done():
+	}
+
+	// A shutdown timeout of 0 waits for as long as it takes, like the other timeouts
+	shutdown, cancel := context.Background(), func() {}
+	if c.Timeouts.Shutdown > 0 {
+		shutdown, cancel = context.WithTimeout(shutdown, c.Timeouts.Shutdown)
+	}
+	defer cancel()
+
+	if e := s.Shutdown(shutdown); e != nil {
+		_ = s.Close() // Requests that are still in progress are cut off
+		return e
+	}
+	if e := <-errs; e != http.ErrServerClosed {
+		return e
+	}
+
+	return nil
+}
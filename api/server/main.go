@@ -1,12 +1,41 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/noahfriedman-ca/quick-factor/api"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"unit.nginx.org/go"
 )
 
 func main() {
-	if e := unit.ListenAndServe(":8080", api.Router()); e != nil {
-		panic(e)
+	c, e := api.LoadConfig(os.Args[1:], os.Getenv)
+	if e != nil {
+		fmt.Fprintln(os.Stderr, e)
+		os.Exit(2)
+	}
+
+	if c.Server == "unit" {
+		if e := unit.ListenAndServe(c.Listen, api.NewRouter(c)); e != nil {
+			panic(e)
+		}
+		return
+	}
+
+	// Stopping the server lets the requests in progress finish first
+	ctx, stop := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		log.Printf("received %s, shutting down", <-signals)
+		stop()
+	}()
+
+	log.Printf("listening on %s", c.Listen)
+	if e := api.Serve(ctx, c, api.NewRouter(c)); e != nil {
+		log.Fatal(e)
 	}
 }
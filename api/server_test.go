@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var _ = Describe("the server", func() {
+	var (
+		l      net.Listener
+		ctx    context.Context
+		stop   context.CancelFunc
+		c      Config
+		served chan error
+	)
+	BeforeEach(func() {
+		var e error
+		l, e = net.Listen("tcp", "127.0.0.1:0")
+		Expect(e).NotTo(HaveOccurred())
+
+		ctx, stop = context.WithCancel(context.Background())
+		c = DefaultConfig()
+		served = make(chan error, 1)
+	})
+	AfterEach(func() {
+		stop()
+	})
+
+	start := func(h http.Handler) {
+		go func() {
+			served <- serve(ctx, c, h, l)
+		}()
+	}
+
+	It("should serve requests until it is stopped", func() {
+		start(NewRouter(c))
+
+		r, e := http.Get("http://" + l.Addr().String() + "/projects/quick-factor/api/factor?expr=x%5E2-1")
+		Expect(e).NotTo(HaveOccurred())
+		Expect(r.StatusCode).To(Equal(http.StatusOK))
+		Expect(r.Body.Close()).To(Succeed())
+
+		stop()
+		Eventually(served).Should(Receive(BeNil()))
+		_, e = http.Get("http://" + l.Addr().String() + "/projects/quick-factor/api/factor?expr=x%5E2-1")
+		Expect(e).To(HaveOccurred())
+	})
+	It("should let requests in progress finish when it is stopped", func() {
+		entered, release := make(chan bool), make(chan bool)
+		start(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			entered <- true
+			<-release
+			_, _ = w.Write([]byte("done"))
+		}))
+
+		responses := make(chan string, 1)
+		go func() {
+			defer GinkgoRecover()
+			r, e := http.Get("http://" + l.Addr().String())
+			Expect(e).NotTo(HaveOccurred())
+			b, e := ioutil.ReadAll(r.Body)
+			Expect(e).NotTo(HaveOccurred())
+			responses <- string(b)
+		}()
+
+		Eventually(entered).Should(Receive())
+		stop()
+		Consistently(served, 100*time.Millisecond).ShouldNot(Receive())
+
+		close(release)
+		Eventually(responses).Should(Receive(Equal("done")))
+		Eventually(served).Should(Receive(BeNil()))
+	})
+	It("should give up on requests that take longer than the shutdown timeout", func() {
+		c.Timeouts.Shutdown = 50 * time.Millisecond
+		entered, release := make(chan bool), make(chan bool)
+		defer close(release)
+		start(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			entered <- true
+			<-release
+		}))
+
+		go func() {
+			_, _ = http.Get("http://" + l.Addr().String())
+		}()
+		Eventually(entered).Should(Receive())
+
+		stop()
+		Eventually(served).Should(Receive(Equal(context.DeadlineExceeded)))
+	})
+	It("should serve HTTPS with a certificate", func() {
+		dir, e := ioutil.TempDir("", "quick-factor")
+		Expect(e).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		// A self-signed certificate is enough to show that the files are used
+		key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(e).NotTo(HaveOccurred())
+		cert, e := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "127.0.0.1"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}, &x509.Certificate{SerialNumber: big.NewInt(1)}, &key.PublicKey, key)
+		Expect(e).NotTo(HaveOccurred())
+		der, e := x509.MarshalECPrivateKey(key)
+		Expect(e).NotTo(HaveOccurred())
+
+		c.TLS.CertFile, c.TLS.KeyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+		Expect(ioutil.WriteFile(c.TLS.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(c.TLS.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)).To(Succeed())
+		start(NewRouter(c))
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+		r, e := client.Get("https://" + l.Addr().String() + "/projects/quick-factor/api/factor?expr=x%5E2-1")
+		Expect(e).NotTo(HaveOccurred())
+		Expect(r.StatusCode).To(Equal(http.StatusOK))
+		Expect(r.TLS).NotTo(BeNil())
+		Expect(r.Body.Close()).To(Succeed())
+	})
+})